@@ -0,0 +1,157 @@
+package dbmanager
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+// sqlStateError represents a driver error exposing its SQLSTATE code, such as
+// `*pq.Error` and `*pgconn.PgError`
+type sqlStateError interface {
+	error
+	SQLState() string
+}
+
+// AssertExists asserts that there are records of the relation specified by
+// `tableName` matching the values set by the given RelationValuesOption
+func (dbMan *dbManager) AssertExists(
+	tableName string,
+	opts ...RelationValuesOption,
+) {
+	dbMan.assertExists(tableName, filterValues(opts...))
+}
+
+func (dbMan *dbManager) assertExists(tableName string, where RelationValues) {
+	if !dbMan.exists(tableName, where) {
+		dbMan.t.Errorf("expected record for '%s' matching %v to exist", tableName, where)
+	}
+}
+
+// AssertAllExist asserts that there are records of the relation specified by
+// `tableName` matching each of the given rows values, reporting all the
+// missing ones at once
+func (dbMan *dbManager) AssertAllExist(tableName string, rows []RelationValues) {
+	var missing []string
+	for i, where := range rows {
+		if !dbMan.exists(tableName, where) {
+			missing = append(missing, fmt.Sprintf("\t%d: %v", i, where))
+		}
+	}
+	if len(missing) > 0 {
+		dbMan.t.Errorf(
+			"expected %d records for '%s' to exist, %d are missing:\n%s",
+			len(rows), tableName, len(missing), strings.Join(missing, "\n"),
+		)
+	}
+}
+
+// checkViolation is the SQLSTATE error code of check constraint violations
+const checkViolation = "23514"
+
+// AssertCreateError asserts that creating a record for the relation specified
+// by `tableName` fails with the given SQLSTATE error code (e.g. `23505` for
+// unique violations).
+// The record is inserted without ignoring conflicts, so unique constraints are
+// enforced. If it's unexpectedly created, it's deleted right away.
+func (dbMan *dbManager) AssertCreateError(
+	tableName string,
+	errCode string,
+	opts ...RelationValuesOption,
+) {
+	values := dbMan.relationValues(tableName, opts...)
+	key, err := dbMan.insertStrict(tableName, values)
+	if err == nil {
+		dbMan.t.Errorf("expected creating record for '%s' to fail with SQLSTATE %s, but it succeeded", tableName, errCode)
+		dbMan.removeCreated(tableName, values, key)
+		return
+	}
+
+	var stateErr sqlStateError
+	if !errors.As(err, &stateErr) {
+		dbMan.t.Errorf("expected creating record for '%s' to fail with SQLSTATE %s, got: %+v", tableName, errCode, err)
+		return
+	}
+	if code := stateErr.SQLState(); code != errCode {
+		dbMan.t.Errorf(
+			"expected creating record for '%s' to fail with SQLSTATE %s, got SQLSTATE %s: %+v",
+			tableName, errCode, code, err,
+		)
+	}
+}
+
+// insertStrict inserts the given values without handling conflicts, returning
+// the inserted row primary key values (nil if the dialect can't read them back)
+func (dbMan *dbManager) insertStrict(tableName string, values RelationValues) (RelationValues, error) {
+	query := dbMan.insertValues(tableName, values)
+	pk := dbMan.primaryKey(tableName)
+	if key, ok := clientKey(values, pk); ok {
+		_, err := dbMan.exec(tableName, query)
+		return key, err
+	}
+	if dbMan.isView(tableName) {
+		_, err := dbMan.exec(tableName, query)
+		return nil, err
+	}
+
+	switch dbMan.dialect.InsertedKey() {
+	case KeyLastInsertID:
+		res, err := dbMan.exec(tableName, query)
+		if err != nil || len(pk) != 1 {
+			return nil, err
+		}
+		if id, err := res.LastInsertId(); err == nil && id != 0 {
+			return RelationValues{pk[0]: id}, nil
+		}
+		return nil, nil
+	case KeyOutput:
+		return dbMan.scanKey(tableName, outputInsert{query: query, output: dbMan.quoteColumns(pk)}, pk)
+	default:
+		return dbMan.scanKey(tableName, query.Suffix("RETURNING "+strings.Join(dbMan.quoteColumns(pk), ", ")), pk)
+	}
+}
+
+// scanKey runs the given query, scanning the returned row into the values of
+// the given primary key columns
+func (dbMan *dbManager) scanKey(tableName string, query sq.Sqlizer, pk []string) (RelationValues, error) {
+	keyValues := make([]interface{}, len(pk))
+	dest := make([]interface{}, len(pk))
+	for i := range keyValues {
+		dest[i] = &keyValues[i]
+	}
+	if err := dbMan.scanRow(tableName, query, dest...); err != nil {
+		return nil, err
+	}
+
+	key := make(RelationValues, len(pk))
+	for i, c := range pk {
+		key[c] = keyValues[i]
+	}
+	return key, nil
+}
+
+// removeCreated deletes the record unexpectedly created with the given values
+// and key, so it doesn't affect other tests
+func (dbMan *dbManager) removeCreated(tableName string, values RelationValues, key RelationValues) {
+	if key == nil {
+		dbMan.t.Errorf("the record created for '%s' couldn't be removed (its key can't be read back)", tableName)
+		return
+	}
+
+	_, call := splitCallOptions(values)
+	_, err := dbMan.exec(tableName, dbMan.deleteBuilder(call.table(tableName)).Where(dbMan.where(key)))
+	if err != nil {
+		dbMan.t.Errorf("the record created for '%s' (%v) couldn't be removed: %+v", tableName, key, err)
+	}
+}
+
+// AssertCheckViolation asserts that creating a record for the relation
+// specified by `tableName` fails due to a check constraint violation
+func (dbMan *dbManager) AssertCheckViolation(
+	tableName string,
+	opts ...RelationValuesOption,
+) {
+	dbMan.AssertCreateError(tableName, checkViolation, opts...)
+}
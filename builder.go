@@ -0,0 +1,92 @@
+package dbmanager
+
+// RelationBuilder accumulates the options for a relation, exposing the
+// DBManager methods in a fluent way, e.g.
+// `dbMan.On("users").Set("email", email).Create().ID()`.
+// Each method returns a new RelationBuilder, so builders can be reused as a
+// base for several records.
+type RelationBuilder struct {
+	dbMan     *dbManager
+	tableName string
+	opts      []RelationValuesOption
+}
+
+// CreatedRecord represents a record created by a RelationBuilder
+type CreatedRecord struct {
+	dbMan     *dbManager
+	tableName string
+	key       RelationValues
+}
+
+// On returns a RelationBuilder for the relation specified by `tableName`
+func (dbMan *dbManager) On(tableName string) *RelationBuilder {
+	return &RelationBuilder{dbMan: dbMan, tableName: tableName}
+}
+
+// Set returns a builder setting the value of the field `f` to `v`
+func (b *RelationBuilder) Set(f string, v interface{}) *RelationBuilder {
+	return b.With(SetFieldValue(f, v))
+}
+
+// With returns a builder applying the given options as well
+func (b *RelationBuilder) With(opts ...RelationValuesOption) *RelationBuilder {
+	allOpts := make([]RelationValuesOption, 0, len(b.opts)+len(opts))
+	allOpts = append(allOpts, b.opts...)
+	allOpts = append(allOpts, opts...)
+	return &RelationBuilder{dbMan: b.dbMan, tableName: b.tableName, opts: allOpts}
+}
+
+// Create creates a new record with the accumulated options, returning it
+func (b *RelationBuilder) Create() *CreatedRecord {
+	return &CreatedRecord{
+		dbMan:     b.dbMan,
+		tableName: b.tableName,
+		key:       b.dbMan.createKey(b.tableName, b.opts...),
+	}
+}
+
+// CreateReturning creates a new record with the accumulated options,
+// returning its key (see `DBManager.CreateReturning`)
+func (b *RelationBuilder) CreateReturning() interface{} {
+	return b.dbMan.CreateReturning(b.tableName, b.opts...)
+}
+
+// Materialize returns the values `Create` would insert
+func (b *RelationBuilder) Materialize() RelationValues {
+	return b.dbMan.Materialize(b.tableName, b.opts...)
+}
+
+// Count returns the number of records matching the accumulated options
+func (b *RelationBuilder) Count() int {
+	return b.dbMan.Count(b.tableName, b.opts...)
+}
+
+// Exists returns whether there are records matching the accumulated options
+func (b *RelationBuilder) Exists() bool {
+	return b.dbMan.Exists(b.tableName, b.opts...)
+}
+
+// AssertExists asserts that there are records matching the accumulated options
+func (b *RelationBuilder) AssertExists() {
+	b.dbMan.AssertExists(b.tableName, b.opts...)
+}
+
+// ID returns the primary key value of the record.
+// It fails the test for relations with composite primary keys (use Key).
+func (r *CreatedRecord) ID() interface{} {
+	pk, err := r.dbMan.singlePrimaryKey(r.tableName)
+	if err != nil {
+		r.dbMan.t.Fatalf("could not get the id of the record for '%s': %+v", r.tableName, err)
+	}
+	return r.key[pk]
+}
+
+// Key returns the primary key values of the record
+func (r *CreatedRecord) Key() RelationValues {
+	return r.key
+}
+
+// AssertExists asserts that the record still exists
+func (r *CreatedRecord) AssertExists() {
+	r.dbMan.assertExists(r.tableName, r.key)
+}
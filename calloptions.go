@@ -0,0 +1,79 @@
+package dbmanager
+
+import "strings"
+
+// callOptionPrefix prefixes the keys set by the RelationValuesOption that
+// configure a single call rather than setting a field value.
+// These keys are removed from the values before building any SQL.
+const callOptionPrefix = "\x00dbmanager:"
+
+const (
+	targetTableOption = callOptionPrefix + "target_table"
+	conflictOption    = callOptionPrefix + "conflict"
+	columnsOption     = callOptionPrefix + "columns"
+)
+
+// callOptions represents the configuration of a single call
+type callOptions struct {
+	targetTable string
+	conflict    *ConflictStrategy
+	columns     []string
+}
+
+// table returns the table the call targets for the given relation
+func (call callOptions) table(relation string) string {
+	if call.targetTable != "" {
+		return call.targetTable
+	}
+	return relation
+}
+
+// WithTargetTable is used for creating a RelationValuesOption that inserts the
+// record into `tableName` (e.g. a specific partition) while still resolving
+// the default values (and configuration) of the given relation
+func WithTargetTable(tableName string) RelationValuesOption {
+	return func(values RelationValues) {
+		values[targetTableOption] = tableName
+	}
+}
+
+// WithConflict is used for creating a RelationValuesOption that sets how unique
+// constraints conflicts are handled when creating the record, overriding the
+// strategy configured for the dbManager
+func WithConflict(strategy ConflictStrategy) RelationValuesOption {
+	return func(values RelationValues) {
+		values[conflictOption] = strategy
+	}
+}
+
+// Columns is used for creating a RelationValuesOption that restricts the
+// columns read by Fetch, FetchOne and FetchInto to the given ones
+func Columns(cols ...string) RelationValuesOption {
+	return func(values RelationValues) {
+		values[columnsOption] = cols
+	}
+}
+
+// splitCallOptions returns a copy of the given values without the call option
+// keys, along with the call options they set
+func splitCallOptions(values RelationValues) (RelationValues, callOptions) {
+	var call callOptions
+	fields := make(RelationValues, len(values))
+	for k, v := range values {
+		if !strings.HasPrefix(k, callOptionPrefix) {
+			fields[k] = v
+			continue
+		}
+
+		switch k {
+		case targetTableOption:
+			call.targetTable = v.(string)
+		case conflictOption:
+			strategy := v.(ConflictStrategy)
+			call.conflict = &strategy
+		case columnsOption:
+			call.columns = v.([]string)
+		}
+	}
+	return fields, call
+}
@@ -0,0 +1,42 @@
+package dbmanager
+
+// ChainContext holds the records created by CreateChain keyed by the alias of
+// the step that created them
+type ChainContext map[string]RelationValues
+
+// ChainStep represents a single record creation of a CreateChain.
+// `Options` receives the records created by the previous steps, so their
+// returned values can be used for creating the current one.
+type ChainStep struct {
+	Table   string
+	Alias   string
+	Options func(ChainContext) []RelationValuesOption
+}
+
+// CreateChain creates a record for each of the given steps, in order.
+// The whole inserted row of each step is stored in the returned ChainContext
+// under the step alias (using RETURNING).
+func (dbMan *dbManager) CreateChain(steps ...ChainStep) ChainContext {
+	ctx := make(ChainContext, len(steps))
+	for _, step := range steps {
+		var opts []RelationValuesOption
+		if step.Options != nil {
+			opts = step.Options(ctx)
+		}
+
+		values := dbMan.relationValues(step.Table, opts...)
+		record, err := dbMan.insertRecord(step.Table, values)
+		if err != nil {
+			dbMan.t.Fatalf("Test setup failed: could not create test record for '%s' (%s): %+v", step.Table, step.Alias, err)
+		}
+		if record == nil {
+			dbMan.t.Fatalf(
+				"Test setup failed: test record for '%s' (%s) was not created (conflicting record already exists)",
+				step.Table, step.Alias,
+			)
+		}
+		ctx[step.Alias] = record
+	}
+
+	return ctx
+}
@@ -0,0 +1,74 @@
+package dbmanager
+
+import (
+	"database/sql"
+	"testing"
+)
+
+// Setup returns a DBManager that keeps track of every record it creates and
+// removes them once the test finishes, leaving unrelated data untouched.
+// `seed` is called with the manager for creating the test baseline records.
+func Setup(
+	t *testing.T,
+	db *sql.DB,
+	defaultValues map[string]RelationValues,
+	seed func(DBManager),
+	opts ...Option,
+) DBManager {
+	dbMan := New(db, t, defaultValues, opts...).(*dbManager)
+	dbMan.tracker = &tracker{}
+	t.Cleanup(dbMan.purge)
+
+	if seed != nil {
+		seed(dbMan)
+	}
+	return dbMan
+}
+
+// CreateWithUndo creates a new record for the relation specified by
+// `tableName`, returning a function that deletes exactly that record.
+// Passing RelationValuesOption overrides the default value set in the creator.
+func (dbMan *dbManager) CreateWithUndo(
+	tableName string,
+	opts ...RelationValuesOption,
+) func() {
+	key := dbMan.createKey(tableName, opts...)
+	db := dbMan.db
+	return func() {
+		_, err := dbMan.withDB(db).exec(tableName, dbMan.deleteBuilder(tableName).Where(dbMan.where(key)))
+		if err != nil {
+			dbMan.t.Fatalf("Test cleanup failed: could not delete test record for '%s' (%v): %+v", tableName, key, err)
+		}
+	}
+}
+
+// trackedRecord represents a record created by the dbManager
+type trackedRecord struct {
+	db        *sql.DB
+	tableName string
+	key       RelationValues
+}
+
+// tracker keeps the records created by the dbManager in creation order
+type tracker struct {
+	records []trackedRecord
+}
+
+func (tr *tracker) track(db *sql.DB, tableName string, key RelationValues) {
+	tr.records = append(tr.records, trackedRecord{db: db, tableName: tableName, key: key})
+}
+
+// purge deletes all the tracked records in the reverse order they were created,
+// so records referencing others are deleted first
+func (dbMan *dbManager) purge() {
+	records := dbMan.tracker.records
+	dbMan.tracker.records = nil
+
+	for i := len(records) - 1; i >= 0; i-- {
+		r := records[i]
+		_, err := dbMan.withDB(r.db).exec(r.tableName, dbMan.deleteBuilder(r.tableName).Where(dbMan.where(r.key)))
+		if err != nil {
+			dbMan.t.Fatalf("Test cleanup failed: could not delete test record for '%s' (%v): %+v", r.tableName, r.key, err)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package dbmanager
+
+import (
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+// clock represents the frozen clock state, shared by the copies of a
+// dbManager
+type clock struct {
+	frozen bool
+	at     time.Time
+}
+
+// ClockValue represents a field value generated from the current time every
+// time a record is created (e.g. `now.Add(-24 * time.Hour)` for yesterday).
+// Unlike generators calling `time.Now`, clock values follow the clock frozen
+// with WithFrozenClock.
+type ClockValue func(now time.Time) interface{}
+
+// GenNow is a ClockValue generating the current time
+var GenNow = ClockValue(func(now time.Time) interface{} { return now })
+
+// Now returns the current time, as given by the frozen clock (if frozen) or
+// the configured now function (see WithNowFunc).
+// Time generators should use it (or ClockValue) to follow a frozen clock.
+func (dbMan *dbManager) Now() time.Time {
+	if dbMan.clock.frozen {
+		return dbMan.clock.at
+	}
+	return dbMan.nowFunc()
+}
+
+// WithFrozenClock runs `fn` with the clock used for the current time (by
+// timestamp columns, ClockValue values and Now) frozen at `at`, restoring it once
+// `fn` returns (or panics). The frozen clock can be moved with AdvanceClock.
+func (dbMan *dbManager) WithFrozenClock(at time.Time, fn func()) {
+	prev := *dbMan.clock
+	defer func() {
+		*dbMan.clock = prev
+	}()
+
+	*dbMan.clock = clock{frozen: true, at: at}
+	fn()
+}
+
+// AdvanceClock moves the frozen clock forward by `d` (or backwards for
+// negative durations). It must be called within WithFrozenClock.
+func (dbMan *dbManager) AdvanceClock(d time.Duration) {
+	if !dbMan.clock.frozen {
+		dbMan.t.Fatalf("could not advance the clock: the clock is not frozen (see WithFrozenClock)")
+	}
+	dbMan.clock.at = dbMan.clock.at.Add(d)
+}
+
+// evaluateClockValues replaces the clock values with their values for the
+// current time
+func (dbMan *dbManager) evaluateClockValues(values RelationValues) {
+	var now time.Time
+	for k, v := range values {
+		if gen, ok := v.(ClockValue); ok {
+			if now.IsZero() {
+				now = dbMan.Now()
+			}
+			values[k] = gen(now)
+		}
+	}
+}
+
+// stampTimestamps sets the WithTimestamps columns of the given relation values
+// to the current time
+func (dbMan *dbManager) stampTimestamps(relationName string, values RelationValues) {
+	if len(dbMan.timestampColumns) == 0 {
+		return
+	}
+
+	now := dbMan.Now()
+	for _, c := range dbMan.timestampColumns {
+		v, declared := values[c]
+		switch {
+		case declared && isDynamicValue(v):
+			continue
+		case declared, dbMan.schemaCache != nil && dbMan.hasKeyColumn(dbMan.tableSchema(relationName), c):
+			values[c] = now
+		}
+	}
+}
+
+// isDynamicValue returns whether the given value is evaluated when creating
+// the record (e.g. an expression or a generator) rather than a plain value
+func isDynamicValue(v interface{}) bool {
+	switch v.(type) {
+	case sq.Sqlizer, Generator, func() interface{}, ClockValue, FakeValue, Computed, func(RelationValues) interface{}:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,51 @@
+package dbmanager
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+func TestFrozenClock(t *testing.T) {
+	dbMan := New(nil, t, nil)
+	at := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	yesterday := ClockValue(func(now time.Time) interface{} { return now.Add(-24 * time.Hour) })
+
+	dbMan.WithFrozenClock(at, func() {
+		values := dbMan.Materialize("events", SetFieldValue("created_at", GenNow), SetFieldValue("due_at", yesterday))
+		if values["created_at"] != at || values["due_at"] != at.Add(-24*time.Hour) {
+			t.Errorf("expected values at the frozen clock %v, got %v", at, values)
+		}
+
+		dbMan.AdvanceClock(time.Hour)
+		if now := dbMan.Now(); !now.Equal(at.Add(time.Hour)) {
+			t.Errorf("expected the advanced clock at %v, got %v", at.Add(time.Hour), now)
+		}
+	})
+
+	if now := dbMan.Now(); now.Equal(at.Add(time.Hour)) {
+		t.Errorf("expected the clock to be restored, got %v", now)
+	}
+}
+
+func TestTimestamps(t *testing.T) {
+	at := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	expr := sq.Expr("CURRENT_TIMESTAMP")
+	dbMan := New(nil, t, map[string]RelationValues{
+		"users":     {"created_at": nil},
+		"events":    {"created_at": expr},
+		"audit_log": {"msg": "created"},
+	}, WithTimestamps("created_at"), WithNowFunc(func() time.Time { return at }))
+
+	if v := dbMan.Materialize("users")["created_at"]; v != at {
+		t.Errorf("expected declared timestamp column to be set to %v, got %v", at, v)
+	}
+	if v := dbMan.Materialize("events")["created_at"]; !reflect.DeepEqual(v, expr) {
+		t.Errorf("expected declared expression to be kept, got %v", v)
+	}
+	if v, ok := dbMan.Materialize("audit_log")["created_at"]; ok {
+		t.Errorf("expected undeclared timestamp column not to be set, got %v", v)
+	}
+}
@@ -0,0 +1,59 @@
+package dbmanager
+
+// CloneRow creates a copy of the record of the relation specified by
+// `tableName` matching `where`, which must match exactly one record.
+// The primary key of the copy is left for the db to generate, the
+// WithTimestamps columns are set to the current time and the WithVersionColumn
+// column is reset to its start value.
+// Passing RelationValuesOption overrides the values of the copied record.
+func (dbMan *dbManager) CloneRow(
+	tableName string,
+	where RelationValues,
+	opts ...RelationValuesOption,
+) {
+	rows, err := dbMan.query(tableName, dbMan.selectBuilder(tableName, "*").Where(dbMan.where(where)))
+	if err != nil {
+		dbMan.t.Fatalf("Test setup failed: could not read the record to clone for '%s': %+v", tableName, err)
+	}
+	records, err := scanRows(rows)
+	if err != nil {
+		dbMan.t.Fatalf("Test setup failed: could not read the record to clone for '%s': %+v", tableName, err)
+	}
+	if len(records) != 1 {
+		dbMan.t.Fatalf(
+			"Test setup failed: could not clone record for '%s': %d records match %v",
+			tableName, len(records), where,
+		)
+	}
+
+	// the columns generated on creation are left for the db to generate or
+	// reset, matching them by the names reported by the db
+	values := records[0]
+	for _, c := range dbMan.primaryKey(tableName) {
+		if k, ok := columnKey(values, c); ok {
+			delete(values, k)
+		}
+	}
+	if vc, ok := dbMan.versionColumns[tableName]; ok {
+		if k, ok := columnKey(values, vc.column); ok {
+			values[k] = vc.start
+		}
+	}
+	if len(dbMan.timestampColumns) > 0 {
+		now := dbMan.Now()
+		for _, c := range dbMan.timestampColumns {
+			if k, ok := columnKey(values, c); ok {
+				values[k] = now
+			}
+		}
+	}
+	values = dbMan.resolveValues(tableName, values, opts...)
+
+	created, err := dbMan.insertRow(tableName, values, nil, nil)
+	if err != nil {
+		dbMan.t.Fatalf("Test setup failed: could not create cloned record for '%s': %+v", tableName, err)
+	}
+	if !created {
+		dbMan.t.Fatalf("Test setup failed: cloned record for '%s' was not created (conflicting record already exists)", tableName)
+	}
+}
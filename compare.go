@@ -0,0 +1,151 @@
+package dbmanager
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// normalizeValue converts the given value to a canonical type, so values of
+// the same kind compare equal regardless of the type the driver scanned them
+// as (e.g. int32 and int64, or []byte and string)
+func normalizeValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case int:
+		return int64(v)
+	case int8:
+		return int64(v)
+	case int16:
+		return int64(v)
+	case int32:
+		return int64(v)
+	case uint:
+		return normalizeUint(uint64(v))
+	case uint8:
+		return int64(v)
+	case uint16:
+		return int64(v)
+	case uint32:
+		return int64(v)
+	case uint64:
+		return normalizeUint(v)
+	case float32:
+		return float64(v)
+	case []byte:
+		return string(v)
+	default:
+		return v
+	}
+}
+
+// normalizeUint converts the given value to int64, or to float64 if it
+// overflows int64
+func normalizeUint(v uint64) interface{} {
+	if v > math.MaxInt64 {
+		return float64(v)
+	}
+	return int64(v)
+}
+
+// normalizeOperands normalizes the given values. Raw driver text (e.g.
+// postgresql numerics, or values read through the mysql text protocol) is
+// parsed as a number when the other value is a number; text is never parsed
+// otherwise, so it compares as the db would.
+func normalizeOperands(a, b interface{}) (interface{}, interface{}) {
+	na, nb := normalizeValue(a), normalizeValue(b)
+	if raw, ok := a.([]byte); ok && isNumber(nb) {
+		na = parseNumber(string(raw))
+	}
+	if raw, ok := b.([]byte); ok && isNumber(na) {
+		nb = parseNumber(string(raw))
+	}
+	return na, nb
+}
+
+// isNumber returns whether the given normalized value is a number
+func isNumber(v interface{}) bool {
+	switch v.(type) {
+	case int64, float64:
+		return true
+	default:
+		return false
+	}
+}
+
+// parseNumber converts numeric text to int64 or float64, leaving any other
+// text as is
+func parseNumber(v string) interface{} {
+	if i, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return i
+	}
+	if f, err := strconv.ParseFloat(v, 64); err == nil && !math.IsNaN(f) && !math.IsInf(f, 0) {
+		return f
+	}
+	return v
+}
+
+// compareValues returns -1, 0 or 1 if a is respectively lower than, equal to
+// or greater than b. Null values and values of different kinds can't be
+// compared.
+func compareValues(a, b interface{}) (int, error) {
+	a, b = normalizeOperands(a, b)
+	if a == nil || b == nil {
+		return 0, fmt.Errorf("null values can't be compared")
+	}
+
+	switch x := a.(type) {
+	case int64:
+		switch y := b.(type) {
+		case int64:
+			return compareOrdered(x < y, x > y), nil
+		case float64:
+			return compareOrdered(float64(x) < y, float64(x) > y), nil
+		}
+	case float64:
+		switch y := b.(type) {
+		case float64:
+			return compareOrdered(x < y, x > y), nil
+		case int64:
+			return compareOrdered(x < float64(y), x > float64(y)), nil
+		}
+	case string:
+		if y, ok := b.(string); ok {
+			return compareOrdered(x < y, x > y), nil
+		}
+	case time.Time:
+		if y, ok := b.(time.Time); ok {
+			return compareOrdered(x.Before(y), x.After(y)), nil
+		}
+	case bool:
+		if y, ok := b.(bool); ok {
+			return compareOrdered(!x && y, x && !y), nil
+		}
+	}
+
+	return 0, fmt.Errorf("values %v (%T) and %v (%T) can't be compared", a, a, b, b)
+}
+
+// valuesEqual returns whether the given values are equal once normalized
+func valuesEqual(a, b interface{}) bool {
+	a, b = normalizeOperands(a, b)
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	if cmp, err := compareValues(a, b); err == nil {
+		return cmp == 0
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func compareOrdered(lower, greater bool) int {
+	switch {
+	case lower:
+		return -1
+	case greater:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,60 @@
+package dbmanager
+
+import "testing"
+
+func TestCompareValues(t *testing.T) {
+	cases := []struct {
+		title string
+		a, b  interface{}
+		exp   int
+	}{
+		{title: "ints of different types", a: int32(9), b: int64(10), exp: -1},
+		{title: "numeric looking text", a: []byte("10"), b: []byte("9"), exp: -1},
+		{title: "mixed text", a: []byte("123"), b: []byte("apple"), exp: -1},
+		{title: "zero padded text", a: "007", b: "7", exp: -1},
+		{title: "numeric text and int", a: []byte("3"), b: 3, exp: 0},
+		{title: "decimal text and float", a: []byte("9.99"), b: 9.99, exp: 0},
+		{title: "unsigned ints", a: uint(2), b: uint64(1), exp: 1},
+		{title: "text", a: []byte("abc"), b: "abd", exp: -1},
+	}
+
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			got, err := compareValues(c.a, c.b)
+			if err != nil {
+				t.Fatalf("unexpected error: %+v", err)
+			}
+			if got != c.exp {
+				t.Errorf("expected %d, got %d", c.exp, got)
+			}
+		})
+	}
+}
+
+func TestValuesEqual(t *testing.T) {
+	cases := []struct {
+		title string
+		a, b  interface{}
+		exp   bool
+	}{
+		{title: "decimal text and float", a: 9.99, b: []byte("9.99"), exp: true},
+		{title: "numeric text and int", a: 3, b: []byte("3"), exp: true},
+		{title: "text and bytes", a: "abc", b: []byte("abc"), exp: true},
+		{title: "nulls", a: nil, b: nil, exp: true},
+		{title: "null and value", a: nil, b: 0, exp: false},
+		{title: "different numbers", a: 3, b: []byte("4"), exp: false},
+		{title: "number and text", a: 3, b: "three", exp: false},
+		{title: "zero padded text", a: "007", b: "7", exp: false},
+		{title: "exponent text", a: "1e3", b: []byte("1000"), exp: false},
+		{title: "zero padded text and bytes", a: "01234", b: []byte("1234"), exp: false},
+		{title: "number and zero padded bytes", a: 7, b: []byte("007"), exp: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			if got := valuesEqual(c.a, c.b); got != c.exp {
+				t.Errorf("expected %t, got %t", c.exp, got)
+			}
+		})
+	}
+}
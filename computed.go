@@ -0,0 +1,120 @@
+package dbmanager
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Computed represents a field value derived from the other field values of
+// the record being created (e.g. a full name from its first and last names).
+// Computed values are evaluated last, once options and generators have been
+// applied, after the computed fields they depend on.
+type Computed struct {
+	fn   func(RelationValues) interface{}
+	deps []string
+}
+
+// Compute returns a computed field value, evaluated by `fn` after the
+// computed fields listed in `deps` (if any) have been evaluated.
+// Plain `func(RelationValues) interface{}` values are handled as computed
+// values without dependencies.
+func Compute(fn func(RelationValues) interface{}, deps ...string) Computed {
+	return Computed{fn: fn, deps: deps}
+}
+
+// evaluateComputed replaces the computed values with their computed values,
+// in dependency order. The test fails if the computed fields depend on each
+// other cyclically.
+func (dbMan *dbManager) evaluateComputed(relationName string, values RelationValues) {
+	computed := map[string]Computed{}
+	for k, v := range values {
+		switch c := v.(type) {
+		case Computed:
+			computed[k] = c
+		case func(RelationValues) interface{}:
+			computed[k] = Computed{fn: c}
+		}
+	}
+	if len(computed) == 0 {
+		return
+	}
+
+	fields := make([]string, 0, len(computed))
+	deps := make(map[string][]string, len(computed))
+	for field, c := range computed {
+		fields = append(fields, field)
+		for _, d := range c.deps {
+			if _, ok := computed[d]; ok {
+				deps[field] = append(deps[field], d)
+			}
+		}
+	}
+
+	sort.Strings(fields)
+	order, err := topoSort(fields, deps)
+	if err != nil {
+		dbMan.t.Fatalf("Test setup failed: invalid computed values for '%s': %+v", relationName, err)
+	}
+	for _, field := range order {
+		values[field] = computed[field].fn(values)
+	}
+}
+
+// topoSort sorts `nodes` so that every node comes after the nodes it depends
+// on (as given by `deps`), otherwise keeping their order.
+// Dependencies that are not in `nodes` are ignored. It returns an error if
+// there's a dependency cycle.
+func topoSort(nodes []string, deps map[string][]string) ([]string, error) {
+	known := make(map[string]bool, len(nodes))
+	for _, n := range nodes {
+		known[n] = true
+	}
+
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := make(map[string]int, len(nodes))
+	order := make([]string, 0, len(nodes))
+	var path []string
+
+	var visit func(n string) error
+	visit = func(n string) error {
+		switch state[n] {
+		case visited:
+			return nil
+		case visiting:
+			for i, p := range path {
+				if p == n {
+					cycle := append(path[i:len(path):len(path)], n)
+					return fmt.Errorf("dependency cycle %s", strings.Join(cycle, " -> "))
+				}
+			}
+		}
+
+		state[n] = visiting
+		path = append(path, n)
+		nodeDeps := append([]string(nil), deps[n]...)
+		sort.Strings(nodeDeps)
+		for _, d := range nodeDeps {
+			if !known[d] {
+				continue
+			}
+			if err := visit(d); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[n] = visited
+		order = append(order, n)
+		return nil
+	}
+
+	for _, n := range nodes {
+		if err := visit(n); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
@@ -0,0 +1,45 @@
+package dbmanager
+
+import (
+	"database/sql/driver"
+	"sync"
+)
+
+// Converter represents a function converting values of the types it knows to
+// the value bound to the generated SQL. It returns false for values of any
+// other type.
+type Converter func(interface{}) (driver.Value, bool)
+
+var (
+	convertersMu sync.RWMutex
+	converters   []Converter
+)
+
+// RegisterConverter registers a Converter used for all records created (and
+// values records are filtered by) by any dbManager (e.g. for mapping custom
+// types to their db representation).
+// Converters are tried in registration order; the first one accepting a value
+// converts it.
+func RegisterConverter(c Converter) {
+	convertersMu.Lock()
+	defer convertersMu.Unlock()
+	converters = append(converters, c)
+}
+
+// convertValues converts the given values with the registered converters
+func convertValues(values RelationValues) {
+	convertersMu.RLock()
+	defer convertersMu.RUnlock()
+	if len(converters) == 0 {
+		return
+	}
+
+	for k, v := range values {
+		for _, c := range converters {
+			if converted, ok := c(v); ok {
+				values[k] = converted
+				break
+			}
+		}
+	}
+}
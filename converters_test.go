@@ -0,0 +1,29 @@
+package dbmanager
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"testing"
+)
+
+// money is a custom value type bound as its amount in cents
+type money float64
+
+func TestWhereConvertsValues(t *testing.T) {
+	RegisterConverter(func(v interface{}) (driver.Value, bool) {
+		m, ok := v.(money)
+		if !ok {
+			return nil, false
+		}
+		return int64(m * 100), true
+	})
+
+	dbMan := New(nil, t, nil).(*dbManager)
+	_, args, err := dbMan.where(filterValues(SetFieldValue("price", money(5)))).ToSql()
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if exp := []interface{}{int64(500)}; !reflect.DeepEqual(args, exp) {
+		t.Errorf("expected args %v, got %v", exp, args)
+	}
+}
@@ -0,0 +1,185 @@
+package dbmanager
+
+import (
+	"fmt"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+// Dialect represents the database the generated SQL is built for
+type Dialect int
+
+const (
+	// DialectPostgres builds SQL for postgresql (default)
+	DialectPostgres Dialect = iota
+	// DialectMySQL builds SQL for mysql
+	DialectMySQL
+	// DialectSQLServer builds SQL for sql server.
+	// Conflicting records can't be skipped on sql server, so inserts always
+	// fail on unique constraints violations.
+	DialectSQLServer
+)
+
+// InsertedKey represents how a dialect reads back the key generated for an
+// inserted record
+type InsertedKey int
+
+const (
+	// KeyReturning reads the key with a `RETURNING` clause
+	KeyReturning InsertedKey = iota
+	// KeyLastInsertID reads the auto-increment id of the record from the
+	// driver result
+	KeyLastInsertID
+	// KeyOutput reads the key with an `OUTPUT INSERTED` clause
+	KeyOutput
+)
+
+// WithDialect is used for creating an Option that builds the SQL for the
+// given database
+func WithDialect(d Dialect) Option {
+	return func(dbMan *dbManager) {
+		dbMan.dialect = d
+		dbMan.queryBuilder = dbMan.queryBuilder.PlaceholderFormat(d.placeholderFormat())
+	}
+}
+
+func (d Dialect) String() string {
+	switch d {
+	case DialectMySQL:
+		return "mysql"
+	case DialectSQLServer:
+		return "sqlserver"
+	default:
+		return "postgresql"
+	}
+}
+
+func (d Dialect) placeholderFormat() sq.PlaceholderFormat {
+	switch d {
+	case DialectMySQL:
+		return sq.Question
+	case DialectSQLServer:
+		return sq.AtP
+	default:
+		return sq.Dollar
+	}
+}
+
+// ignoreConflicts makes the given insert skip records violating unique
+// constraints. On mysql, a no-op update of `column` (a quoted key column) is
+// used, since `INSERT IGNORE` would also skip records violating other
+// constraints (e.g. foreign keys or not null ones).
+func (d Dialect) ignoreConflicts(query sq.InsertBuilder, column string) sq.InsertBuilder {
+	switch d {
+	case DialectMySQL:
+		return query.Suffix(fmt.Sprintf("ON DUPLICATE KEY UPDATE %s = %s", column, column))
+	case DialectSQLServer:
+		return query
+	default:
+		return query.Suffix("ON CONFLICT DO NOTHING")
+	}
+}
+
+// columnHasDefault returns the information schema columns expression
+// evaluating to 1 for columns with a default value (including identity and
+// auto-increment columns), and to 0 otherwise
+func (d Dialect) columnHasDefault() string {
+	switch d {
+	case DialectMySQL:
+		return "CASE WHEN column_default IS NOT NULL OR extra LIKE '%auto_increment%' THEN 1 ELSE 0 END"
+	case DialectSQLServer:
+		return "CASE WHEN column_default IS NOT NULL OR " +
+			"COLUMNPROPERTY(OBJECT_ID(QUOTENAME(table_schema) + '.' + QUOTENAME(table_name)), column_name, 'IsIdentity') = 1 " +
+			"THEN 1 ELSE 0 END"
+	default:
+		return "CASE WHEN column_default IS NOT NULL OR is_identity = 'YES' THEN 1 ELSE 0 END"
+	}
+}
+
+// limitOne limits the given query to its first row
+func (d Dialect) limitOne(query sq.SelectBuilder) sq.SelectBuilder {
+	switch d {
+	case DialectSQLServer:
+		return query.Options("TOP 1")
+	default:
+		return query.Limit(1)
+	}
+}
+
+// currentSchema returns the expression evaluating to the schema unqualified
+// table names are resolved in
+func (d Dialect) currentSchema() sq.Sqlizer {
+	switch d {
+	case DialectMySQL:
+		return sq.Expr("DATABASE()")
+	case DialectSQLServer:
+		return sq.Expr("SCHEMA_NAME()")
+	default:
+		return sq.Expr("current_schema()")
+	}
+}
+
+// InsertedKey returns how the dialect reads back the key generated for an
+// inserted record
+func (d Dialect) InsertedKey() InsertedKey {
+	switch d {
+	case DialectMySQL:
+		return KeyLastInsertID
+	case DialectSQLServer:
+		return KeyOutput
+	default:
+		return KeyReturning
+	}
+}
+
+func (d Dialect) supportsReturning() bool {
+	return d.InsertedKey() == KeyReturning
+}
+
+func (d Dialect) errReturningNotSupported() error {
+	return fmt.Errorf("RETURNING is not supported by %s", d)
+}
+
+// isView returns whether the given relation was marked as a view
+func (dbMan *dbManager) isView(relation string) bool {
+	_, ok := dbMan.views[relation]
+	return ok
+}
+
+// supportsReturning returns whether records of the given relation can be
+// created using RETURNING
+func (dbMan *dbManager) supportsReturning(relation string) bool {
+	return dbMan.dialect.supportsReturning() && !dbMan.isView(relation)
+}
+
+func (dbMan *dbManager) errReturningNotSupported(relation string) error {
+	if dbMan.isView(relation) {
+		return fmt.Errorf("RETURNING is not supported for view '%s'", relation)
+	}
+	return dbMan.dialect.errReturningNotSupported()
+}
+
+// outputInsert represents an insert reading back the given columns of the
+// inserted rows with an `OUTPUT INSERTED` clause
+type outputInsert struct {
+	query  sq.InsertBuilder
+	output []string
+}
+
+func (oi outputInsert) ToSql() (string, []interface{}, error) {
+	sqlStr, args, err := oi.query.ToSql()
+	if err != nil {
+		return "", nil, err
+	}
+
+	i := strings.Index(sqlStr, " VALUES ")
+	if i < 0 {
+		return "", nil, fmt.Errorf("could not find the VALUES clause of %q", sqlStr)
+	}
+	inserted := make([]string, len(oi.output))
+	for j, c := range oi.output {
+		inserted[j] = "INSERTED." + c
+	}
+	return sqlStr[:i] + " OUTPUT " + strings.Join(inserted, ", ") + sqlStr[i:], args, nil
+}
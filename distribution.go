@@ -0,0 +1,57 @@
+package dbmanager
+
+import (
+	"fmt"
+	"sort"
+)
+
+// CreateDistributed creates `n` records for the relation specified by
+// `tableName`, setting `field` of each record to one of the keys of `weights`,
+// picked randomly with the probability given by its weight (e.g.
+// `{"active": 0.7, "inactive": 0.3}`).
+// Use WithSeed for reproducible distributions.
+// Passing RelationValuesOption overrides the default value set in the creator.
+func (dbMan *dbManager) CreateDistributed(
+	tableName string,
+	n int,
+	field string,
+	weights map[interface{}]float64,
+	opts ...RelationValuesOption,
+) {
+	// map iteration order is random, so values are sorted for the picks to
+	// be reproducible for a given seed
+	values := make([]interface{}, 0, len(weights))
+	var total float64
+	for v, w := range weights {
+		if w < 0 {
+			dbMan.t.Fatalf("Test setup failed: negative weight %v for '%s' value %v", w, field, v)
+		}
+		values = append(values, v)
+		total += w
+	}
+	if total <= 0 {
+		dbMan.t.Fatalf("Test setup failed: no positive weights given for '%s'", field)
+	}
+	sort.Slice(values, func(i, j int) bool {
+		return fmt.Sprint(values[i]) < fmt.Sprint(values[j])
+	})
+
+	rowOpts := make([]RelationValuesOption, len(opts)+1)
+	copy(rowOpts, opts)
+	for i := 0; i < n; i++ {
+		rowOpts[len(opts)] = SetFieldValue(field, dbMan.pickWeighted(values, weights, total))
+		dbMan.Create(tableName, rowOpts...)
+	}
+}
+
+// pickWeighted randomly picks one of the given values according to its weight
+func (dbMan *dbManager) pickWeighted(values []interface{}, weights map[interface{}]float64, total float64) interface{} {
+	r := dbMan.rand.Float64() * total
+	for _, v := range values {
+		r -= weights[v]
+		if r < 0 {
+			return v
+		}
+	}
+	return values[len(values)-1]
+}
@@ -0,0 +1,137 @@
+package dbmanager
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+// runner runs queries, either directly on a db or within a transaction
+type runner interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// conn returns the runner queries are run on: the current transaction, if
+// any, or the db
+func (dbMan *dbManager) conn() runner {
+	if dbMan.tx != nil {
+		return dbMan.tx
+	}
+	return dbMan.db
+}
+
+// exec runs the given query against the db
+func (dbMan *dbManager) exec(tableName string, query sq.Sqlizer) (sql.Result, error) {
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		dbMan.observe(tableName, sqlStr, time.Now(), err)
+		return nil, queryError(tableName, query, err)
+	}
+
+	dbMan.debugQuery(sqlStr, args)
+	start := time.Now()
+	res, err := dbMan.conn().Exec(sqlStr, args...)
+	dbMan.observe(tableName, sqlStr, start, err)
+	if err != nil {
+		return nil, queryError(tableName, query, err)
+	}
+	return res, nil
+}
+
+// query runs the given query against the db, returning the resulting rows
+func (dbMan *dbManager) query(tableName string, query sq.Sqlizer) (*sql.Rows, error) {
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		dbMan.observe(tableName, sqlStr, time.Now(), err)
+		return nil, queryError(tableName, query, err)
+	}
+
+	dbMan.debugQuery(sqlStr, args)
+	start := time.Now()
+	rows, err := dbMan.conn().Query(sqlStr, args...)
+	dbMan.observe(tableName, sqlStr, start, err)
+	if err != nil {
+		return nil, queryError(tableName, query, err)
+	}
+	return rows, nil
+}
+
+// scanRow runs the given query against the db, scanning the resulting row
+// into dest.
+// `sql.ErrNoRows` is returned (wrapped) when the query returns no rows.
+func (dbMan *dbManager) scanRow(tableName string, query sq.Sqlizer, dest ...interface{}) error {
+	sqlStr, args, err := query.ToSql()
+	if err != nil {
+		dbMan.observe(tableName, sqlStr, time.Now(), err)
+		return queryError(tableName, query, err)
+	}
+
+	dbMan.debugQuery(sqlStr, args)
+	start := time.Now()
+	err = dbMan.conn().QueryRow(sqlStr, args...).Scan(dest...)
+	dbMan.observe(tableName, sqlStr, start, err)
+	if err != nil {
+		return queryError(tableName, query, err)
+	}
+	return nil
+}
+
+// debugQuery logs the given statement when debugging is enabled
+func (dbMan *dbManager) debugQuery(sqlStr string, args []interface{}) {
+	if dbMan.debug {
+		dbMan.t.Logf("dbmanager: %s %v", sqlStr, args)
+	}
+}
+
+// observe calls the configured observers for a statement started at `start`
+func (dbMan *dbManager) observe(tableName string, sqlStr string, start time.Time, err error) {
+	if len(dbMan.observers) == 0 {
+		return
+	}
+
+	dur := time.Since(start)
+	for _, observer := range dbMan.observers {
+		observer(tableName, sqlStr, dur, err)
+	}
+}
+
+// WithDB returns a copy of the DBManager running its queries against db,
+// sharing the default values and configuration (e.g. for seeding a primary
+// and asserting on a replica)
+func (dbMan *dbManager) WithDB(db *sql.DB) DBManager {
+	return dbMan.withDB(db)
+}
+
+// withDB returns a copy of the dbManager running its queries against db
+func (dbMan *dbManager) withDB(db *sql.DB) *dbManager {
+	cp := *dbMan
+	cp.db = db
+	cp.tx = nil
+	return &cp
+}
+
+// queryError wraps err with the SQL generated by query, so failures show
+// exactly what was sent to the db
+func queryError(tableName string, query sq.Sqlizer, err error) error {
+	op := "query on"
+	switch query.(type) {
+	case sq.InsertBuilder, outputInsert:
+		op = "insert into"
+	case sq.SelectBuilder:
+		op = "select from"
+	case sq.UpdateBuilder:
+		op = "update of"
+	case sq.DeleteBuilder:
+		op = "delete from"
+	}
+
+	sqlStr, args, sqlErr := query.ToSql()
+	if sqlErr != nil {
+		return fmt.Errorf("%s %s failed: %w", op, tableName, err)
+	}
+	return fmt.Errorf("%s %s failed (%s %v): %w", op, tableName, sqlStr, args, err)
+}
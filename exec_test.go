@@ -0,0 +1,27 @@
+package dbmanager
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestQueryError(t *testing.T) {
+	db, d := newStubDB(t)
+	d.execErr = errors.New("duplicate key value")
+
+	dbMan := New(db, t, nil).(*dbManager)
+	_, err := dbMan.exec("users", dbMan.insert("users", RelationValues{"username": "tlins"}))
+	if err == nil {
+		t.Fatalf("expected the insert to fail")
+	}
+
+	for _, exp := range []string{"insert into users failed", `INSERT INTO users ("username")`, "[tlins]", "duplicate key value"} {
+		if !strings.Contains(err.Error(), exp) {
+			t.Errorf("expected error to contain %q, got %q", exp, err)
+		}
+	}
+	if !errors.Is(err, d.execErr) {
+		t.Errorf("expected error to wrap the driver error, got %v", err)
+	}
+}
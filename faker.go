@@ -0,0 +1,39 @@
+package dbmanager
+
+// Faker represents a source of realistic fake data. It's implemented by the
+// users (e.g. wrapping their faker library of choice) and set with WithFaker.
+type Faker interface {
+	Name() string
+	Email() string
+	Username() string
+	Text() string
+}
+
+// FakeValue represents a field value generated with the configured Faker
+// every time a record is created, e.g.:
+//
+//	"email": dbmanager.FakeValue(func(f dbmanager.Faker) interface{} { return f.Email() })
+type FakeValue func(Faker) interface{}
+
+// WithFaker is used for creating an Option that sets the Faker used for
+// generating FakeValue values.
+func WithFaker(f Faker) Option {
+	return func(dbMan *dbManager) {
+		dbMan.faker = f
+	}
+}
+
+// evaluateFakes replaces the fake values with values generated with the
+// configured Faker. The test fails if there's no Faker configured.
+func (dbMan *dbManager) evaluateFakes(relationName string, values RelationValues) {
+	for k, v := range values {
+		fake, ok := v.(FakeValue)
+		if !ok {
+			continue
+		}
+		if dbMan.faker == nil {
+			dbMan.t.Fatalf("Test setup failed: no faker configured for generating '%s.%s' (see WithFaker)", relationName, k)
+		}
+		values[k] = fake(dbMan.faker)
+	}
+}
@@ -0,0 +1,179 @@
+package dbmanager
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// FetchInto reads the records of the relation specified by `tableName` into
+// `dest`, which must be a pointer to a slice of structs (or struct pointers).
+// Columns are matched to the struct fields by their `db` tag; nullable columns
+// should be mapped to pointer or `sql.Null*` fields.
+// Passing RelationValuesOption filters the fetched records by the given values.
+func (dbMan *dbManager) FetchInto(
+	dest interface{},
+	tableName string,
+	opts ...RelationValuesOption,
+) {
+	slice := reflect.ValueOf(dest)
+	if slice.Kind() != reflect.Ptr || slice.Elem().Kind() != reflect.Slice {
+		dbMan.t.Fatalf("could not fetch records for '%s': dest must be a pointer to a slice, got %T", tableName, dest)
+	}
+	slice = slice.Elem()
+
+	elemType := slice.Type().Elem()
+	structType := elemType
+	if elemType.Kind() == reflect.Ptr {
+		structType = elemType.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		dbMan.t.Fatalf("could not fetch records for '%s': dest must be a slice of structs, got %T", tableName, dest)
+	}
+
+	rows, err := dbMan.fetchRows(tableName, opts...)
+	if err != nil {
+		dbMan.t.Fatalf("could not fetch records for '%s': %+v", tableName, err)
+	}
+	if err := scanStructs(rows, slice, structType); err != nil {
+		dbMan.t.Fatalf("could not fetch records for '%s': %+v", tableName, err)
+	}
+}
+
+// Fetch reads the records of the relation specified by `tableName`.
+// Passing RelationValuesOption filters the fetched records by the given values,
+// and Columns restricts the read columns.
+func (dbMan *dbManager) Fetch(
+	tableName string,
+	opts ...RelationValuesOption,
+) []RelationValues {
+	rows, err := dbMan.fetchRows(tableName, opts...)
+	if err != nil {
+		dbMan.t.Fatalf("could not fetch records for '%s': %+v", tableName, err)
+	}
+	records, err := scanRows(rows)
+	if err != nil {
+		dbMan.t.Fatalf("could not fetch records for '%s': %+v", tableName, err)
+	}
+	return records
+}
+
+// FetchOne reads the single record of the relation specified by `tableName`
+// matching the values set by the given RelationValuesOption, failing the test
+// unless exactly one record matches.
+// Columns restricts the read columns.
+func (dbMan *dbManager) FetchOne(
+	tableName string,
+	opts ...RelationValuesOption,
+) RelationValues {
+	records := dbMan.Fetch(tableName, opts...)
+	if len(records) != 1 {
+		dbMan.t.Fatalf("could not fetch record for '%s': %d matching records", tableName, len(records))
+	}
+	return records[0]
+}
+
+// fetchRows queries the records of the given relation matching the given
+// options, selecting only the columns set with Columns (if any)
+func (dbMan *dbManager) fetchRows(tableName string, opts ...RelationValuesOption) (*sql.Rows, error) {
+	where, call := filterOptions(opts...)
+	columns := call.columns
+	if len(columns) == 0 {
+		columns = []string{"*"}
+	}
+
+	query := dbMan.selectBuilder(tableName, columns...).Where(dbMan.where(where))
+	rows, err := dbMan.query(tableName, query)
+	if err != nil {
+		return nil, err
+	}
+	if len(call.columns) == 0 {
+		return rows, nil
+	}
+
+	names, err := rows.Columns()
+	if err != nil {
+		rows.Close()
+		return nil, err
+	}
+	for _, c := range call.columns {
+		if !hasColumnName(names, c) {
+			rows.Close()
+			return nil, fmt.Errorf("column '%s' not found in the result set %v", c, names)
+		}
+	}
+	return rows, nil
+}
+
+// hasColumnName returns whether `names` include the given column, whose
+// case may have been folded by the db
+func hasColumnName(names []string, column string) bool {
+	for _, n := range names {
+		if strings.EqualFold(n, column) {
+			return true
+		}
+	}
+	return false
+}
+
+// scanStructs appends each of the given rows to slice, scanning the columns
+// into the struct fields with the matching `db` tag.
+// The rows are always closed.
+func scanStructs(rows *sql.Rows, slice reflect.Value, structType reflect.Type) error {
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+
+	fields := taggedFields(structType)
+	fieldIndexes := make([]int, len(columns))
+	for i, c := range columns {
+		idx, ok := fields[c]
+		if !ok {
+			return fmt.Errorf("no field of %s is tagged with `db:\"%s\"`", structType, c)
+		}
+		fieldIndexes[i] = idx
+	}
+
+	ptrElems := slice.Type().Elem().Kind() == reflect.Ptr
+	for rows.Next() {
+		elem := reflect.New(structType)
+		dest := make([]interface{}, len(columns))
+		for i, idx := range fieldIndexes {
+			dest[i] = elem.Elem().Field(idx).Addr().Interface()
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return err
+		}
+
+		if ptrElems {
+			slice.Set(reflect.Append(slice, elem))
+		} else {
+			slice.Set(reflect.Append(slice, elem.Elem()))
+		}
+	}
+
+	return rows.Err()
+}
+
+// taggedFields returns the index of the exported fields of the given struct
+// type keyed by their `db` tag
+func taggedFields(structType reflect.Type) map[string]int {
+	fields := make(map[string]int)
+	for i := 0; i < structType.NumField(); i++ {
+		f := structType.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+
+		name := strings.Split(f.Tag.Get("db"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		fields[name] = i
+	}
+	return fields
+}
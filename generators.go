@@ -0,0 +1,72 @@
+package dbmanager
+
+import "github.com/google/uuid"
+
+// maxUniqueAttempts is the number of candidates generated by UniqueInDB
+// before giving up
+const maxUniqueAttempts = 100
+
+// Generator represents a field value generated every time a record is
+// created (e.g. sequential or random values for unique fields).
+// Both default values and values set through RelationValuesOption may be
+// generators; plain `func() interface{}` values are handled the same way.
+type Generator func() interface{}
+
+// generation represents the dbManager evaluating generators, shared by the
+// copies of a dbManager, so generators created with UniqueInDB query the db
+// the same way as the record being created (e.g. within a Batch transaction)
+type generation struct {
+	dbMan *dbManager
+}
+
+// evaluateGenerators replaces the generator values with their generated
+// values
+func (dbMan *dbManager) evaluateGenerators(values RelationValues) {
+	prev := dbMan.generation.dbMan
+	dbMan.generation.dbMan = dbMan
+	defer func() {
+		dbMan.generation.dbMan = prev
+	}()
+
+	for k, v := range values {
+		switch gen := v.(type) {
+		case Generator:
+			values[k] = gen()
+		case func() interface{}:
+			values[k] = gen()
+		}
+	}
+}
+
+// GenUUID returns a new random UUID.
+// Being a `func() interface{}`, it can also be used directly as a generator.
+func GenUUID() interface{} {
+	return uuid.New().String()
+}
+
+// UniqueInDB returns a Generator producing values of `gen` that are not yet
+// present in `column` of the relation specified by `tableName`.
+// Candidates are generated until a value not found in the db is produced,
+// failing the test after a bounded number of attempts.
+// The db is checked through the manager creating the record, so records
+// created but not yet committed by a Batch are taken into account.
+func (dbMan *dbManager) UniqueInDB(tableName string, column string, gen func() interface{}) Generator {
+	return func() interface{} {
+		checker := dbMan.generation.dbMan
+		if checker == nil {
+			checker = dbMan
+		}
+		for i := 0; i < maxUniqueAttempts; i++ {
+			candidate := gen()
+			if !checker.exists(tableName, RelationValues{column: candidate}) {
+				return candidate
+			}
+		}
+
+		dbMan.t.Fatalf(
+			"Test setup failed: could not generate a unique '%s' value for '%s' after %d attempts",
+			column, tableName, maxUniqueAttempts,
+		)
+		return nil
+	}
+}
@@ -4,5 +4,6 @@ go 1.15
 
 require (
 	github.com/Masterminds/squirrel v1.4.0
+	github.com/google/uuid v1.6.0
 	github.com/lann/builder v0.0.0-20180802200727-47ae307949d0
 )
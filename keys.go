@@ -0,0 +1,42 @@
+package dbmanager
+
+import "fmt"
+
+// defaultPrimaryKey is the primary key column of relations without a
+// configured one
+const defaultPrimaryKey = "id"
+
+// primaryKey returns the primary key columns of the given relation: the
+// configured ones, the ones looked up from the information schema when the
+// schema cache is enabled, or the default primary key
+func (dbMan *dbManager) primaryKey(relation string) []string {
+	if pk, ok := dbMan.primaryKeys[relation]; ok && len(pk) > 0 {
+		return pk
+	}
+	if dbMan.schemaCache != nil {
+		if pk := dbMan.tableSchema(relation).primaryKey; len(pk) > 0 {
+			return pk
+		}
+	}
+	return []string{defaultPrimaryKey}
+}
+
+// singlePrimaryKey returns the primary key column of the given relation,
+// failing for composite primary keys
+func (dbMan *dbManager) singlePrimaryKey(relation string) (string, error) {
+	pk := dbMan.primaryKey(relation)
+	if len(pk) != 1 {
+		return "", fmt.Errorf("relation '%s' has a composite primary key (%v)", relation, pk)
+	}
+	return pk[0], nil
+}
+
+// recordKey returns the primary key values of the given record
+func (dbMan *dbManager) recordKey(relation string, record RelationValues) RelationValues {
+	pk := dbMan.primaryKey(relation)
+	key := make(RelationValues, len(pk))
+	for _, c := range pk {
+		key[c] = columnValue(record, c)
+	}
+	return key
+}
@@ -2,7 +2,10 @@ package dbmanager
 
 import (
 	"database/sql"
+	"math/rand"
+	"sort"
 	"testing"
+	"time"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/lann/builder"
@@ -11,6 +14,53 @@ import (
 // DBManager represents the dbManager
 type DBManager interface {
 	Create(string, ...RelationValuesOption)
+	Materialize(string, ...RelationValuesOption) RelationValues
+	CreateScan(string, []interface{}, []string, ...RelationValuesOption)
+	CreateAndGet(string, ...RelationValuesOption) RelationValues
+	CreateChain(...ChainStep) ChainContext
+	CountDistinct(string, string, ...RelationValuesOption) int
+	CreateReturning(string, ...RelationValuesOption) interface{}
+	AssertCreateError(string, string, ...RelationValuesOption)
+	AssertCheckViolation(string, ...RelationValuesOption)
+	CreateDistributed(string, int, string, map[interface{}]float64, ...RelationValuesOption)
+	FetchInto(interface{}, string, ...RelationValuesOption)
+	Fetch(string, ...RelationValuesOption) []RelationValues
+	FetchOne(string, ...RelationValuesOption) RelationValues
+	UniqueInDB(string, string, func() interface{}) Generator
+	AssertReferentialIntegrity()
+	Count(string, ...RelationValuesOption) int
+	Exists(string, ...RelationValuesOption) bool
+	CreateIf(func(DBManager) bool, string, ...RelationValuesOption)
+	RunSQLFile(string)
+	CreateMany(string, [][]RelationValuesOption) []interface{}
+	InvalidateSchemaCache(...string)
+	CreateOn(string, string, ...RelationValuesOption)
+	ResetSequence(string, string)
+	ResetAllSequences()
+	CloneRow(string, RelationValues, ...RelationValuesOption)
+	CreateWithUndo(string, ...RelationValuesOption) func()
+	MustConnect()
+	CreateManyReturning(string, [][]RelationValuesOption, []string) []RelationValues
+	WithDefaults(string, RelationValues, func())
+	CreateFromSelect(string, []string, sq.SelectBuilder)
+	AssertOrdered(string, string, bool, ...RelationValuesOption)
+	AssertExists(string, ...RelationValuesOption)
+	AssertAllExist(string, []RelationValues)
+	AssertTableEquals(string, []RelationValues, ...string)
+	ForEachValue(string, string, func(interface{}), ...RelationValuesOption)
+	GenerateDefaults(...string) map[string]RelationValues
+	InsertedCount() int
+	SkippedCount() int
+	WithDB(*sql.DB) DBManager
+	IncrementVersion(string, RelationValues)
+	SeedOrdered(...string)
+	DeleteReturning(string, []string, ...RelationValuesOption) []RelationValues
+	UpdateReturning(string, RelationValues, []string, ...RelationValuesOption) []RelationValues
+	Now() time.Time
+	WithFrozenClock(time.Time, func())
+	AdvanceClock(time.Duration)
+	Batch(func(DBManager))
+	On(string) *RelationBuilder
 }
 
 // RelationValues represents the models values used for querying the db in tests
@@ -27,20 +77,324 @@ func SetFieldValue(f string, v interface{}) RelationValuesOption {
 	}
 }
 
+// SetDefault is used for creating a RelationValuesOption for inserting a
+// specific field with the `DEFAULT` keyword, so the column default expression
+// is used while the field is still part of the inserted columns
+func SetDefault(f string) RelationValuesOption {
+	return SetFieldValue(f, sq.Expr("DEFAULT"))
+}
+
+// SetFieldExpr is used for creating a RelationValuesOption for setting a
+// specific field's value to the given SQL expression, binding `args` to its
+// placeholders (e.g. `SetFieldExpr("location", "ST_SetSRID(ST_MakePoint(?, ?), 4326)", lng, lat)`)
+func SetFieldExpr(f string, sql string, args ...interface{}) RelationValuesOption {
+	return SetFieldValue(f, sq.Expr(sql, args...))
+}
+
+// SetFieldValues is used for creating a RelationValuesOption for setting
+// all the given fields' values at once (e.g. the result of `Materialize`)
+func SetFieldValues(vs RelationValues) RelationValuesOption {
+	return func(values RelationValues) {
+		for f, v := range vs {
+			values[f] = v
+		}
+	}
+}
+
+// Option represents the option function to be passed into New for
+// configuring the dbManager
+type Option func(*dbManager)
+
+// WithoutConflictSuffix is used for creating an Option that omits the
+// `ON CONFLICT DO NOTHING` suffix when creating records for the given
+// relations (e.g. tables without any unique constraints)
+func WithoutConflictSuffix(relations ...string) Option {
+	return func(dbMan *dbManager) {
+		for _, r := range relations {
+			dbMan.noConflictSuffix[r] = struct{}{}
+		}
+	}
+}
+
+// WithIdentifierQuoting is used for creating an Option that quotes table and
+// column names in the generated SQL according to the given style
+func WithIdentifierQuoting(style QuoteStyle) Option {
+	return func(dbMan *dbManager) {
+		dbMan.quoteStyle = style
+	}
+}
+
+// WithTimestamps is used for creating an Option that sets the given columns
+// to the current time on created records, for the relations whose default
+// values declare them (e.g. as nil) or, with the schema cache enabled, whose
+// table has them.
+// Expressions and generators declared in the default values are kept, and
+// values set through RelationValuesOption still take precedence.
+func WithTimestamps(columns ...string) Option {
+	return func(dbMan *dbManager) {
+		dbMan.timestampColumns = append(dbMan.timestampColumns, columns...)
+	}
+}
+
+// WithNowFunc is used for creating an Option that sets the function used for
+// getting the current time (defaults to `time.Now`)
+func WithNowFunc(now func() time.Time) Option {
+	return func(dbMan *dbManager) {
+		dbMan.nowFunc = now
+	}
+}
+
+// WithPrimaryKey is used for creating an Option that sets the primary key
+// columns of the given relation (defaults to the one looked up from the
+// information schema with WithSchemaCache, or to `id`).
+// The primary key is used for identifying created records (e.g. for cleanup).
+func WithPrimaryKey(relation string, columns ...string) Option {
+	return func(dbMan *dbManager) {
+		dbMan.primaryKeys[relation] = columns
+	}
+}
+
+// WithLeakDetection is used for creating an Option that limits the db to a
+// single open connection for the duration of the test, so any connection left
+// busy (e.g. by unclosed rows) makes the following queries block instead of
+// silently leaking. The previous limit is restored on cleanup.
+func WithLeakDetection() Option {
+	return func(dbMan *dbManager) {
+		db := dbMan.db
+		prevMaxOpenConns := db.Stats().MaxOpenConnections
+		db.SetMaxOpenConns(1)
+		dbMan.t.Cleanup(func() {
+			db.SetMaxOpenConns(prevMaxOpenConns)
+		})
+	}
+}
+
+// WithSeed is used for creating an Option that seeds the random number
+// generator used by the dbManager, making randomized records reproducible
+func WithSeed(seed int64) Option {
+	return func(dbMan *dbManager) {
+		dbMan.rand = rand.New(rand.NewSource(seed))
+	}
+}
+
+// Observer represents a function called after every statement run by the
+// dbManager, with the relation it targets, the generated SQL, how long it took
+// and the error it returned (if any)
+type Observer func(tableName string, sql string, dur time.Duration, err error)
+
+// WithObserver is used for creating an Option that calls the given observer
+// after every statement run by the dbManager (e.g. for profiling test setup)
+func WithObserver(observer Observer) Option {
+	return func(dbMan *dbManager) {
+		dbMan.observers = append(dbMan.observers, observer)
+	}
+}
+
+// SkipMissingColumns is used for creating an Option that drops the values of
+// columns not present in the target table instead of failing, so the same
+// default values can be used against different schema versions.
+// It enables the schema cache, so the columns of each table are looked up once.
+func SkipMissingColumns() Option {
+	return func(dbMan *dbManager) {
+		dbMan.skipMissingColumns = true
+		WithSchemaCache()(dbMan)
+	}
+}
+
+// WithSchemaCache is used for creating an Option that caches the tables schema
+// looked up from the information schema, so it is queried only once per table.
+// The cached schema also provides the primary key of relations without a
+// configured one (see WithPrimaryKey).
+// Use `InvalidateSchemaCache` if the schema changes during the test.
+func WithSchemaCache() Option {
+	return func(dbMan *dbManager) {
+		if dbMan.schemaCache == nil {
+			dbMan.schemaCache = make(map[string]*tableSchema)
+		}
+	}
+}
+
+// WithForeignKey is used for creating an Option that declares that `column`
+// of the given relation references `parentColumn` of `parentRelation`
+func WithForeignKey(relation, column, parentRelation, parentColumn string) Option {
+	return func(dbMan *dbManager) {
+		dbMan.foreignKeys = append(dbMan.foreignKeys, foreignKey{
+			relation:       relation,
+			column:         column,
+			parentRelation: parentRelation,
+			parentColumn:   parentColumn,
+		})
+	}
+}
+
+// WithConnection is used for creating an Option that registers an additional
+// named db connection, to be used with `CreateOn`
+func WithConnection(name string, db *sql.DB) Option {
+	return func(dbMan *dbManager) {
+		dbMan.connections[name] = db
+	}
+}
+
+// WithValidator is used for creating an Option that validates the value of
+// `field` of every record of the given relation, once options are applied.
+// A non-nil error fails the test; fields missing from the values aren't
+// validated.
+func WithValidator(relation, field string, fn func(interface{}) error) Option {
+	return func(dbMan *dbManager) {
+		dbMan.validators[relation] = append(dbMan.validators[relation], fieldValidator{field: field, fn: fn})
+	}
+}
+
+// fieldValidator represents a validation declared with WithValidator
+type fieldValidator struct {
+	field string
+	fn    func(interface{}) error
+}
+
+// ConflictStrategy represents how unique constraints conflicts are handled
+// when creating records
+type ConflictStrategy int
+
+const (
+	// ConflictDoNothing skips records conflicting with existing ones (default)
+	ConflictDoNothing ConflictStrategy = iota
+	// ConflictError fails creating records conflicting with existing ones
+	ConflictError
+)
+
+// WithDefaultConflict is used for creating an Option that sets how unique
+// constraints conflicts are handled by default (`ConflictDoNothing` unless
+// set). `WithConflict` overrides it for a single call.
+func WithDefaultConflict(strategy ConflictStrategy) Option {
+	return func(dbMan *dbManager) {
+		dbMan.defaultConflict = strategy
+	}
+}
+
+// WithPingOnCreate is used for creating an Option that checks the db is
+// reachable when the dbManager is created, failing the test early otherwise
+func WithPingOnCreate() Option {
+	return func(dbMan *dbManager) {
+		dbMan.pingOnCreate = true
+	}
+}
+
+// WithDebug is used for creating an Option that logs every statement run by
+// the dbManager, along with its arguments
+func WithDebug() Option {
+	return func(dbMan *dbManager) {
+		dbMan.debug = true
+	}
+}
+
+// WithView is used for creating an Option that marks the given relations as
+// views (e.g. updatable through `INSTEAD OF` triggers).
+// Records created through views don't handle conflicts nor use RETURNING, so
+// their keys are only known when supplied by the client.
+func WithView(relations ...string) Option {
+	return func(dbMan *dbManager) {
+		for _, r := range relations {
+			dbMan.views[r] = struct{}{}
+		}
+	}
+}
+
+// WithAutoCommit is used for creating an Option that runs each Create in its
+// own transaction, committed once all of its statements succeed.
+func WithAutoCommit() Option {
+	return func(dbMan *dbManager) {
+		dbMan.autoCommit = true
+	}
+}
+
+// createCounts represents the number of records inserted and skipped by
+// Create, shared by the copies of a dbManager
+type createCounts struct {
+	inserted int
+	skipped  int
+}
+
 type dbManager struct {
 	db                    *sql.DB
+	tx                    *sql.Tx
 	t                     *testing.T
 	queryBuilder          sq.StatementBuilderType
 	defaultRelationValues map[string]RelationValues
+	noDefaults            bool
+	noConflictSuffix      map[string]struct{}
+	quoteStyle            QuoteStyle
+	tracker               *tracker
+	dialect               Dialect
+	timestampColumns      []string
+	nowFunc               func() time.Time
+	clock                 *clock
+	primaryKeys           map[string][]string
+	rand                  *rand.Rand
+	observers             []Observer
+	skipMissingColumns    bool
+	schemaCache           map[string]*tableSchema
+	foreignKeys           []foreignKey
+	connections           map[string]*sql.DB
+	validators            map[string][]fieldValidator
+	defaultConflict       ConflictStrategy
+	pingOnCreate          bool
+	debug                 bool
+	views                 map[string]struct{}
+	autoCommit            bool
+	faker                 Faker
+	counts                *createCounts
+	generation            *generation
+	versionColumns        map[string]versionColumn
 }
 
-// New returns a DBManager
-func New(db *sql.DB, t *testing.T, defaultValues map[string]RelationValues) DBManager {
-	return &dbManager{
+// New returns a DBManager.
+// A nil `defaultValues` map means there are no default values: every relation
+// starts out empty, with its values fully specified through options.
+func New(db *sql.DB, t *testing.T, defaultValues map[string]RelationValues, opts ...Option) DBManager {
+	dbMan := &dbManager{
 		db:                    db,
 		t:                     t,
 		queryBuilder:          sq.StatementBuilderType(builder.EmptyBuilder).PlaceholderFormat(sq.Dollar),
 		defaultRelationValues: defaultValues,
+		noDefaults:            defaultValues == nil,
+		noConflictSuffix:      make(map[string]struct{}),
+		views:                 make(map[string]struct{}),
+		nowFunc:               time.Now,
+		clock:                 &clock{},
+		primaryKeys:           make(map[string][]string),
+		connections:           make(map[string]*sql.DB),
+		validators:            make(map[string][]fieldValidator),
+		versionColumns:        make(map[string]versionColumn),
+		rand:                  rand.New(rand.NewSource(time.Now().UnixNano())),
+		counts:                &createCounts{},
+		generation:            &generation{},
+	}
+	for _, opt := range opts {
+		opt(dbMan)
+	}
+	if dbMan.pingOnCreate {
+		dbMan.MustConnect()
+	}
+
+	return dbMan
+}
+
+// MustConnect checks that the db (and every connection registered with
+// WithConnection) is reachable, failing the test otherwise
+func (dbMan *dbManager) MustConnect() {
+	if err := dbMan.db.Ping(); err != nil {
+		dbMan.t.Fatalf("Test setup failed: test database unreachable: %+v", err)
+	}
+
+	names := make([]string, 0, len(dbMan.connections))
+	for name := range dbMan.connections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := dbMan.connections[name].Ping(); err != nil {
+			dbMan.t.Fatalf("Test setup failed: test database '%s' unreachable: %+v", name, err)
+		}
 	}
 }
 
@@ -50,34 +404,320 @@ func (dbMan *dbManager) Create(
 	tableName string,
 	opts ...RelationValuesOption,
 ) {
+	if dbMan.autoCommit && dbMan.tx == nil {
+		err := dbMan.inTx(func(txMan *dbManager) error {
+			txMan.Create(tableName, opts...)
+			return nil
+		})
+		if err != nil {
+			dbMan.t.Fatalf("Test setup failed: could not create test record for '%s': %+v", tableName, err)
+		}
+		return
+	}
+
 	values := dbMan.relationValues(tableName, opts...)
-	_, err := dbMan.insertBuilder(tableName).SetMap(sq.Eq(values)).Suffix("ON CONFLICT DO NOTHING").Query()
-	if err != nil {
-		dbMan.t.Fatalf("Test setup failed: could not create test record for '%s': %+v", tableName, err)
+	var created bool
+	if dbMan.tracker != nil {
+		var err error
+		created, err = dbMan.insertRow(tableName, values, nil, nil)
+		if err != nil {
+			dbMan.t.Fatalf("Test setup failed: could not create test record for '%s': %+v", tableName, err)
+		}
+	} else {
+		res, err := dbMan.exec(tableName, dbMan.insert(tableName, values))
+		if err != nil {
+			dbMan.t.Fatalf("Test setup failed: could not create test record for '%s': %+v", tableName, err)
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			dbMan.t.Fatalf("Test setup failed: could not create test record for '%s': %+v", tableName, err)
+		}
+		created = n > 0
 	}
+
+	if created {
+		dbMan.counts.inserted++
+	} else {
+		dbMan.counts.skipped++
+	}
+}
+
+// InsertedCount returns the number of records inserted by Create so far
+func (dbMan *dbManager) InsertedCount() int {
+	return dbMan.counts.inserted
+}
+
+// SkippedCount returns the number of records Create skipped so far due to
+// conflicting records
+func (dbMan *dbManager) SkippedCount() int {
+	return dbMan.counts.skipped
+}
+
+// CreateOn creates a new record for the relation specified by `tableName`
+// using the connection registered as `dbName` with WithConnection.
+// Passing RelationValuesOption overrides the default value set in the creator.
+func (dbMan *dbManager) CreateOn(
+	dbName string,
+	tableName string,
+	opts ...RelationValuesOption,
+) {
+	db, ok := dbMan.connections[dbName]
+	if !ok {
+		dbMan.t.Fatalf("Test setup failed: no connection registered as '%s'", dbName)
+	}
+	dbMan.withDB(db).Create(tableName, opts...)
+}
+
+// CreateIf creates a new record for the relation specified by `tableName` only
+// if `predicate` returns true.
+// The predicate receives the DBManager, so it can check the db state (e.g.
+// with `Exists` or `Count`).
+func (dbMan *dbManager) CreateIf(
+	predicate func(DBManager) bool,
+	tableName string,
+	opts ...RelationValuesOption,
+) {
+	if predicate(dbMan) {
+		dbMan.Create(tableName, opts...)
+	}
+}
+
+// Materialize returns the values `Create` would insert for the relation
+// specified by `tableName`, without touching the db.
+// It runs the same merge pipeline as `Create`, so the result can be used both
+// for creating the record and for asserting on it afterwards.
+func (dbMan *dbManager) Materialize(
+	tableName string,
+	opts ...RelationValuesOption,
+) RelationValues {
+	values, _ := splitCallOptions(dbMan.relationValues(tableName, opts...))
+	return values
+}
+
+// insert returns the insert query for the given rows values, handling unique
+// constraints conflicts according to the configured strategy
+func (dbMan *dbManager) insert(tableName string, rows ...RelationValues) sq.InsertBuilder {
+	query := dbMan.insertValues(tableName, rows...)
+
+	var call callOptions
+	if len(rows) > 0 {
+		_, call = splitCallOptions(rows[0])
+	}
+	return dbMan.handleConflicts(tableName, call, query)
+}
+
+// handleConflicts makes the given insert query handle unique constraints
+// conflicts according to the configured strategy
+func (dbMan *dbManager) handleConflicts(tableName string, call callOptions, query sq.InsertBuilder) sq.InsertBuilder {
+	// conflicts can't be handled when inserting through views
+	if dbMan.isView(tableName) || dbMan.conflictStrategy(tableName, call) == ConflictError {
+		return query
+	}
+	return dbMan.dialect.ignoreConflicts(query, dbMan.quoteColumn(dbMan.primaryKey(tableName)[0]))
+}
+
+// conflictStrategy returns how unique constraints conflicts are handled when
+// inserting into the given relation: the strategy set for the call takes
+// precedence over the one configured for the relation, which takes precedence
+// over the default one
+func (dbMan *dbManager) conflictStrategy(tableName string, call callOptions) ConflictStrategy {
+	if call.conflict != nil {
+		return *call.conflict
+	}
+	if _, ok := dbMan.noConflictSuffix[tableName]; ok {
+		return ConflictError
+	}
+	return dbMan.defaultConflict
+}
+
+// insertValues returns the insert query for the given rows values, failing on
+// unique constraints conflicts.
+// Columns missing from some of the rows are inserted with `DEFAULT` for them.
+func (dbMan *dbManager) insertValues(tableName string, rows ...RelationValues) sq.InsertBuilder {
+	fields := make([]RelationValues, len(rows))
+	var call callOptions
+	for i, values := range rows {
+		var rowCall callOptions
+		fields[i], rowCall = splitCallOptions(values)
+		if i == 0 {
+			call = rowCall
+		}
+	}
+
+	target := call.table(tableName)
+	columnSet := make(RelationValues)
+	for _, values := range fields {
+		for c := range values {
+			columnSet[c] = nil
+		}
+	}
+	if dbMan.skipMissingColumns {
+		columnSet = dbMan.existingColumnsValues(target, columnSet)
+	}
+	columns := make([]string, 0, len(columnSet))
+	for c := range columnSet {
+		columns = append(columns, c)
+	}
+	sort.Strings(columns)
+
+	query := dbMan.insertBuilder(target).Columns(dbMan.quoteColumns(columns)...)
+	for _, values := range fields {
+		row := make([]interface{}, len(columns))
+		for i, c := range columns {
+			v, ok := values[c]
+			if !ok {
+				v = sq.Expr("DEFAULT")
+			}
+			row[i] = v
+		}
+		query = query.Values(row...)
+	}
+	return query
 }
 
 func (dbMan *dbManager) insertBuilder(tableName string) sq.InsertBuilder {
 	return dbMan.queryBuilder.
-		RunWith(dbMan.db).
-		Insert(tableName)
+		Insert(dbMan.quoteIdent(tableName))
+}
+
+func (dbMan *dbManager) updateBuilder(tableName string) sq.UpdateBuilder {
+	return dbMan.queryBuilder.
+		Update(dbMan.quoteIdent(tableName))
+}
+
+func (dbMan *dbManager) deleteBuilder(tableName string) sq.DeleteBuilder {
+	return dbMan.queryBuilder.
+		Delete(dbMan.quoteIdent(tableName))
+}
+
+func (dbMan *dbManager) selectBuilder(tableName string, columns ...string) sq.SelectBuilder {
+	return dbMan.queryBuilder.
+		Select(dbMan.quoteColumns(columns)...).
+		From(dbMan.quoteIdent(tableName))
+}
+
+// where returns the predicate matching all the given values, converted with
+// the registered converters.
+// Expression values (e.g. set with SetFieldExpr) are compared to the result of
+// evaluating them.
+func (dbMan *dbManager) where(values RelationValues) sq.Sqlizer {
+	converted := make(RelationValues, len(values))
+	columns := make([]string, 0, len(values))
+	for c, v := range values {
+		converted[c] = v
+		columns = append(columns, c)
+	}
+	sort.Strings(columns)
+	convertValues(converted)
+	values = converted
+
+	pred := sq.And{}
+	for _, c := range columns {
+		switch v := values[c].(type) {
+		case sq.Sqlizer, []byte:
+			pred = append(pred, sq.Expr(dbMan.quoteColumn(c)+" = ?", v))
+		default:
+			pred = append(pred, sq.Eq{dbMan.quoteColumn(c): v})
+		}
+	}
+	return pred
+}
+
+// filterValues returns the values set by the given option functions, for
+// filtering records
+func filterValues(opts ...RelationValuesOption) RelationValues {
+	values, _ := filterOptions(opts...)
+	return values
+}
+
+// filterOptions returns the values and call options set by the given option
+// functions, for reading records
+func filterOptions(opts ...RelationValuesOption) (RelationValues, callOptions) {
+	values := make(RelationValues)
+	for _, opt := range opts {
+		opt(values)
+	}
+	return splitCallOptions(values)
 }
 
 // relationValues returns a copy of the default values for a given
-// relation with the applied option functions
+// relation with the applied option functions and evaluated generators
 func (dbMan *dbManager) relationValues(relationName string, opts ...RelationValuesOption) RelationValues {
 	defaultVal := dbMan.getDefaultRelationValues(relationName)
+	dbMan.stampTimestamps(relationName, defaultVal)
+	if vc, ok := dbMan.versionColumns[relationName]; ok {
+		if _, set := defaultVal[vc.column]; !set {
+			defaultVal[vc.column] = vc.start
+		}
+	}
+	return dbMan.resolveValues(relationName, defaultVal, opts...)
+}
+
+// resolveValues applies the given options to the values of a record to be
+// created for the given relation, evaluating its generated and computed values
+// and validating and converting the result
+func (dbMan *dbManager) resolveValues(
+	relationName string,
+	values RelationValues,
+	opts ...RelationValuesOption,
+) RelationValues {
 	for _, opt := range opts {
-		opt(defaultVal)
+		opt(values)
+	}
+	dbMan.evaluateGenerators(values)
+	dbMan.evaluateClockValues(values)
+	dbMan.evaluateFakes(relationName, values)
+	dbMan.evaluateComputed(relationName, values)
+	dbMan.validate(relationName, values)
+	convertValues(values)
+
+	return values
+}
+
+// validate runs the validators of the given relation for the fields present
+// in values
+func (dbMan *dbManager) validate(relationName string, values RelationValues) {
+	for _, v := range dbMan.validators[relationName] {
+		value, ok := values[v.field]
+		if !ok {
+			continue
+		}
+		if err := v.fn(value); err != nil {
+			dbMan.t.Fatalf("Test setup failed: invalid value %v for '%s.%s': %+v", value, relationName, v.field, err)
+		}
+	}
+}
+
+// WithDefaults runs `fn` with the default values of the given relation
+// overridden by `overrides`, restoring the previous default values once it
+// returns (or panics). The default values map given to New isn't modified.
+func (dbMan *dbManager) WithDefaults(relation string, overrides RelationValues, fn func()) {
+	prev := dbMan.defaultRelationValues
+	defer func() {
+		dbMan.defaultRelationValues = prev
+	}()
+
+	scoped := make(map[string]RelationValues, len(prev)+1)
+	for r, values := range prev {
+		scoped[r] = values
+	}
+	merged := make(RelationValues, len(prev[relation])+len(overrides))
+	for k, v := range prev[relation] {
+		merged[k] = v
 	}
+	for k, v := range overrides {
+		merged[k] = v
+	}
+	scoped[relation] = merged
+	dbMan.defaultRelationValues = scoped
 
-	return defaultVal
+	fn()
 }
 
 // getDefaultRelationValues creates a copy of the default value
 func (dbMan *dbManager) getDefaultRelationValues(relationName string) RelationValues {
 	defaultValue, ok := dbMan.defaultRelationValues[relationName]
-	if !ok {
+	if !ok && !dbMan.noDefaults {
 		dbMan.t.Fatalf("no default values for relation '%s'", relationName)
 	}
 
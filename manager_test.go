@@ -0,0 +1,31 @@
+package dbmanager
+
+import "testing"
+
+func TestCreateWithoutDefaultValues(t *testing.T) {
+	db, d := newStubDB(t)
+
+	New(db, t, nil).Create(
+		"users",
+		SetFieldValue("username", "tlins"),
+		SetFieldValue("email", "tlins@example.com"),
+	)
+
+	exp := `INSERT INTO users ("email","username") VALUES ($1,$2) ON CONFLICT DO NOTHING`
+	if len(d.queries) != 1 || d.queries[0] != exp {
+		t.Errorf("expected query %q, got %q", exp, d.queries)
+	}
+}
+
+func TestCreateCounts(t *testing.T) {
+	db, d := newStubDB(t)
+	d.rowsAffected = []int64{1, 0}
+
+	dbMan := New(db, t, map[string]RelationValues{"users": {"username": "tlins"}})
+	dbMan.Create("users")
+	dbMan.Create("users")
+
+	if dbMan.InsertedCount() != 1 || dbMan.SkippedCount() != 1 {
+		t.Errorf("expected 1 inserted and 1 skipped records, got %d and %d", dbMan.InsertedCount(), dbMan.SkippedCount())
+	}
+}
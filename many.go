@@ -0,0 +1,97 @@
+package dbmanager
+
+import "strings"
+
+// CreateMany creates a record for each of the given rows options for the
+// relation specified by `tableName`, in a single statement, returning the key
+// of each created record in the same order as the given rows.
+// The keys are read with RETURNING, so all records must be created (none of
+// them can be skipped due to conflicts).
+func (dbMan *dbManager) CreateMany(
+	tableName string,
+	rows [][]RelationValuesOption,
+) []interface{} {
+	pk, err := dbMan.singlePrimaryKey(tableName)
+	if err != nil {
+		dbMan.t.Fatalf("Test setup failed: could not create test records for '%s': %+v", tableName, err)
+	}
+
+	records := dbMan.createMany(tableName, rows, []string{pk})
+	keys := make([]interface{}, len(records))
+	for i, r := range records {
+		keys[i] = columnValue(r, pk)
+	}
+	return keys
+}
+
+// CreateManyReturning creates a record for each of the given rows options for
+// the relation specified by `tableName`, in a single statement, returning the
+// `returning` columns of each created record in the same order as the given
+// rows.
+// All records must be created (none of them can be skipped due to conflicts).
+func (dbMan *dbManager) CreateManyReturning(
+	tableName string,
+	rows [][]RelationValuesOption,
+	returning []string,
+) []RelationValues {
+	records := dbMan.createMany(tableName, rows, returning)
+	for i, r := range records {
+		projected := make(RelationValues, len(returning))
+		for _, c := range returning {
+			projected[c] = columnValue(r, c)
+		}
+		records[i] = projected
+	}
+	return records
+}
+
+// createMany creates a record for each of the given rows options in a single
+// statement, returning the `returning` columns of each created record
+func (dbMan *dbManager) createMany(
+	tableName string,
+	rows [][]RelationValuesOption,
+	returning []string,
+) []RelationValues {
+	if len(rows) == 0 {
+		return nil
+	}
+	if !dbMan.supportsReturning(tableName) {
+		dbMan.t.Fatalf(
+			"Test setup failed: could not create test records for '%s': %+v",
+			tableName, dbMan.errReturningNotSupported(tableName),
+		)
+	}
+
+	values := make([]RelationValues, len(rows))
+	for i, opts := range rows {
+		values[i] = dbMan.relationValues(tableName, opts...)
+	}
+
+	if dbMan.tracker != nil {
+		returning = append(returning[:len(returning):len(returning)], dbMan.primaryKey(tableName)...)
+	}
+
+	query := dbMan.insert(tableName, values...).Suffix("RETURNING " + strings.Join(dbMan.quoteColumns(returning), ", "))
+	res, err := dbMan.query(tableName, query)
+	if err != nil {
+		dbMan.t.Fatalf("Test setup failed: could not create test records for '%s': %+v", tableName, err)
+	}
+	records, err := scanRows(res)
+	if err != nil {
+		dbMan.t.Fatalf("Test setup failed: could not create test records for '%s': %+v", tableName, err)
+	}
+
+	if dbMan.tracker != nil {
+		for _, r := range records {
+			dbMan.tracker.track(dbMan.db, tableName, dbMan.recordKey(tableName, r))
+		}
+	}
+	if len(records) != len(rows) {
+		dbMan.t.Fatalf(
+			"Test setup failed: only %d of %d test records for '%s' were created (conflicting records already exist)",
+			len(records), len(rows), tableName,
+		)
+	}
+
+	return records
+}
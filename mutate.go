@@ -0,0 +1,79 @@
+package dbmanager
+
+import (
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+// DeleteReturning deletes the records of the relation specified by
+// `tableName` matching the values set by the given RelationValuesOption,
+// returning the `returning` columns (all of them if none is given) of the
+// deleted records
+func (dbMan *dbManager) DeleteReturning(
+	tableName string,
+	returning []string,
+	opts ...RelationValuesOption,
+) []RelationValues {
+	query := dbMan.deleteBuilder(tableName).
+		Where(dbMan.where(filterValues(opts...))).
+		Suffix(dbMan.returningClause(returning))
+	records, err := dbMan.queryReturning(tableName, query)
+	if err != nil {
+		dbMan.t.Fatalf("could not delete records for '%s': %+v", tableName, err)
+	}
+	return records
+}
+
+// UpdateReturning sets the `set` values on the records of the relation
+// specified by `tableName` matching the values set by the given
+// RelationValuesOption, returning the `returning` columns (all of them if none
+// is given) of the updated records
+func (dbMan *dbManager) UpdateReturning(
+	tableName string,
+	set RelationValues,
+	returning []string,
+	opts ...RelationValuesOption,
+) []RelationValues {
+	values := make(RelationValues, len(set))
+	for k, v := range set {
+		values[k] = v
+	}
+	convertValues(values)
+
+	setMap := make(map[string]interface{}, len(values))
+	for k, v := range values {
+		setMap[dbMan.quoteColumn(k)] = v
+	}
+	query := dbMan.updateBuilder(tableName).
+		SetMap(setMap).
+		Where(dbMan.where(filterValues(opts...))).
+		Suffix(dbMan.returningClause(returning))
+	records, err := dbMan.queryReturning(tableName, query)
+	if err != nil {
+		dbMan.t.Fatalf("could not update records for '%s': %+v", tableName, err)
+	}
+	return records
+}
+
+// returningClause returns the RETURNING clause for the given columns (all of
+// them if none is given)
+func (dbMan *dbManager) returningClause(returning []string) string {
+	if len(returning) == 0 {
+		return "RETURNING *"
+	}
+	return "RETURNING " + strings.Join(dbMan.quoteColumns(returning), ", ")
+}
+
+// queryReturning runs the given RETURNING query, scanning the returned rows
+func (dbMan *dbManager) queryReturning(tableName string, query sq.Sqlizer) ([]RelationValues, error) {
+	if !dbMan.supportsReturning(tableName) {
+		return nil, dbMan.errReturningNotSupported(tableName)
+	}
+
+	rows, err := dbMan.query(tableName, query)
+	if err != nil {
+		return nil, err
+	}
+	return scanRows(rows)
+}
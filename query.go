@@ -0,0 +1,141 @@
+package dbmanager
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// Count returns the number of records of the relation specified by
+// `tableName`.
+// Passing RelationValuesOption filters the counted records by the given values.
+func (dbMan *dbManager) Count(
+	tableName string,
+	opts ...RelationValuesOption,
+) int {
+	var count int
+	query := dbMan.selectBuilder(tableName).
+		Column("count(*)").
+		Where(dbMan.where(filterValues(opts...)))
+	err := dbMan.scanRow(tableName, query, &count)
+	if err != nil {
+		dbMan.t.Fatalf("could not count records for '%s': %+v", tableName, err)
+	}
+
+	return count
+}
+
+// Exists returns whether there are records of the relation specified by
+// `tableName` matching the values set by the given RelationValuesOption
+func (dbMan *dbManager) Exists(
+	tableName string,
+	opts ...RelationValuesOption,
+) bool {
+	return dbMan.exists(tableName, filterValues(opts...))
+}
+
+func (dbMan *dbManager) exists(tableName string, where RelationValues) bool {
+	var found int
+	query := dbMan.dialect.limitOne(dbMan.selectBuilder(tableName).
+		Column("1").
+		Where(dbMan.where(where)))
+	err := dbMan.scanRow(tableName, query, &found)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false
+	}
+	if err != nil {
+		dbMan.t.Fatalf("could not check records existence for '%s': %+v", tableName, err)
+	}
+
+	return true
+}
+
+// AssertOrdered asserts that the values of `column` of the records of the
+// relation specified by `tableName` are sorted (ascending, or descending if
+// `desc` is set) when the records are read in primary key order.
+// Null values are reported as unordered.
+// Passing RelationValuesOption filters the checked records by the given values.
+func (dbMan *dbManager) AssertOrdered(
+	tableName string,
+	column string,
+	desc bool,
+	opts ...RelationValuesOption,
+) {
+	query := dbMan.selectBuilder(tableName, column).
+		Where(dbMan.where(filterValues(opts...))).
+		OrderBy(dbMan.quoteColumns(dbMan.primaryKey(tableName))...)
+	rows, err := dbMan.query(tableName, query)
+	if err != nil {
+		dbMan.t.Fatalf("could not read '%s' values for '%s': %+v", column, tableName, err)
+	}
+	records, err := scanRows(rows)
+	if err != nil {
+		dbMan.t.Fatalf("could not read '%s' values for '%s': %+v", column, tableName, err)
+	}
+
+	order := "ascending"
+	if desc {
+		order = "descending"
+	}
+	for i := 1; i < len(records); i++ {
+		prev, cur := columnValue(records[i-1], column), columnValue(records[i], column)
+		cmp, err := compareValues(prev, cur)
+		if err != nil {
+			dbMan.t.Errorf("'%s.%s' values at %d and %d are not in %s order: %+v", tableName, column, i-1, i, order, err)
+			return
+		}
+		if desc && cmp < 0 || !desc && cmp > 0 {
+			dbMan.t.Errorf("'%s.%s' values at %d (%v) and %d (%v) are not in %s order", tableName, column, i-1, prev, i, cur, order)
+			return
+		}
+	}
+}
+
+// CountDistinct returns the number of distinct values of `column` among the
+// records of the relation specified by `tableName`.
+// Passing RelationValuesOption filters the counted records by the given values.
+func (dbMan *dbManager) CountDistinct(
+	tableName string,
+	column string,
+	opts ...RelationValuesOption,
+) int {
+	var count int
+	query := dbMan.selectBuilder(tableName).
+		Column(fmt.Sprintf("count(DISTINCT %s)", dbMan.quoteColumn(column))).
+		Where(dbMan.where(filterValues(opts...)))
+	err := dbMan.scanRow(tableName, query, &count)
+	if err != nil {
+		dbMan.t.Fatalf("could not count distinct '%s' values for '%s': %+v", column, tableName, err)
+	}
+
+	return count
+}
+
+// ForEachValue calls `fn` once for each distinct value of `column` among the
+// records of the relation specified by `tableName`, in ascending order.
+// All values are read before `fn` is first called, so it's free to query the
+// db (e.g. running a subtest per value).
+// Passing RelationValuesOption filters the iterated records by the given values.
+func (dbMan *dbManager) ForEachValue(
+	tableName string,
+	column string,
+	fn func(v interface{}),
+	opts ...RelationValuesOption,
+) {
+	query := dbMan.selectBuilder(tableName, column).
+		Distinct().
+		Where(dbMan.where(filterValues(opts...))).
+		OrderBy(dbMan.quoteColumn(column))
+	rows, err := dbMan.query(tableName, query)
+	if err != nil {
+		dbMan.t.Fatalf("could not read distinct '%s' values for '%s': %+v", column, tableName, err)
+	}
+	records, err := scanRows(rows)
+	if err != nil {
+		dbMan.t.Fatalf("could not read distinct '%s' values for '%s': %+v", column, tableName, err)
+	}
+
+	for _, r := range records {
+		fn(columnValue(r, column))
+	}
+}
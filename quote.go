@@ -0,0 +1,151 @@
+package dbmanager
+
+import "strings"
+
+// QuoteStyle represents how identifiers are quoted in the generated SQL
+type QuoteStyle int
+
+const (
+	// QuoteNone leaves table names as they are (default).
+	// Column names are still quoted when that can't change their meaning, so
+	// reserved words (e.g. `order`) can be used as column names.
+	QuoteNone QuoteStyle = iota
+	// QuoteDouble quotes identifiers with double quotes (e.g. postgresql)
+	QuoteDouble
+	// QuoteBacktick quotes identifiers with backticks (e.g. mysql)
+	QuoteBacktick
+)
+
+func (style QuoteStyle) quote() string {
+	switch style {
+	case QuoteDouble:
+		return `"`
+	case QuoteBacktick:
+		return "`"
+	default:
+		return ""
+	}
+}
+
+// quoteIdent quotes the given identifier according to the configured style.
+// Each segment of qualified names (e.g. `schema.table`) is quoted separately,
+// and segments which are already quoted are left as they are.
+func (dbMan *dbManager) quoteIdent(name string) string {
+	q := dbMan.quoteStyle.quote()
+	if q == "" {
+		return name
+	}
+
+	segments := splitIdent(name)
+	for i, s := range segments {
+		if s == "*" || isQuoted(s) {
+			continue
+		}
+		segments[i] = q + strings.ReplaceAll(s, q, q+q) + q
+	}
+	return strings.Join(segments, ".")
+}
+
+// quoteColumn quotes the given column name according to the configured
+// style. Without a configured style, plain identifiers are quoted in the way
+// the dialect would interpret them unquoted (e.g. lower cased on postgresql, as is on
+// mysql and sql server),
+// so reserved words are handled without changing which column is referenced.
+func (dbMan *dbManager) quoteColumn(name string) string {
+	if dbMan.quoteStyle != QuoteNone {
+		return dbMan.quoteIdent(name)
+	}
+
+	segments := splitIdent(name)
+	for i, s := range segments {
+		if !isPlainIdent(s) {
+			continue
+		}
+		switch dbMan.dialect {
+		case DialectMySQL:
+			segments[i] = "`" + s + "`"
+		case DialectSQLServer:
+			// sql server doesn't fold the case of unquoted identifiers
+			segments[i] = `"` + s + `"`
+		default:
+			segments[i] = `"` + foldIdent(s) + `"`
+		}
+	}
+	return strings.Join(segments, ".")
+}
+
+// quoteColumns quotes each of the given column names
+func (dbMan *dbManager) quoteColumns(names []string) []string {
+	quoted := make([]string, len(names))
+	for i, n := range names {
+		quoted[i] = dbMan.quoteColumn(n)
+	}
+	return quoted
+}
+
+// splitIdent splits the given qualified identifier on the dots which are not
+// part of a quoted segment
+func splitIdent(name string) []string {
+	var (
+		segments []string
+		start    int
+		quote    rune
+	)
+	for i, c := range name {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '`':
+			quote = c
+		case c == '.':
+			segments = append(segments, name[start:i])
+			start = i + 1
+		}
+	}
+	return append(segments, name[start:])
+}
+
+// isQuoted returns whether the given identifier segment is already quoted
+func isQuoted(s string) bool {
+	if len(s) < 2 {
+		return false
+	}
+	return s[0] == '"' && s[len(s)-1] == '"' || s[0] == '`' && s[len(s)-1] == '`'
+}
+
+// unquoteIdent returns the name of the given (possibly quoted) identifier
+// segment, as the db reports it
+func unquoteIdent(s string) string {
+	if !isQuoted(s) {
+		return s
+	}
+	q := s[:1]
+	return strings.ReplaceAll(s[1:len(s)-1], q+q, q)
+}
+
+// isPlainIdent returns whether the given identifier segment is made only of
+// letters, digits and underscores (e.g. it isn't quoted or an expression)
+func isPlainIdent(s string) bool {
+	if s == "" || s[0] >= '0' && s[0] <= '9' {
+		return false
+	}
+	for _, c := range s {
+		if !(c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// foldIdent lower cases the ASCII letters of the given identifier, as
+// postgresql does for unquoted identifiers
+func foldIdent(s string) string {
+	return strings.Map(func(c rune) rune {
+		if c >= 'A' && c <= 'Z' {
+			return c + 'a' - 'A'
+		}
+		return c
+	}, s)
+}
@@ -0,0 +1,77 @@
+package dbmanager
+
+import (
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+// foreignKey represents a relationship declared with WithForeignKey
+type foreignKey struct {
+	relation       string
+	column         string
+	parentRelation string
+	parentColumn   string
+}
+
+func (fk foreignKey) String() string {
+	return fmt.Sprintf("%s.%s -> %s.%s", fk.relation, fk.column, fk.parentRelation, fk.parentColumn)
+}
+
+// AssertReferentialIntegrity asserts that none of the relationships declared
+// with WithForeignKey references a nonexistent parent record
+func (dbMan *dbManager) AssertReferentialIntegrity() {
+	for _, fk := range dbMan.foreignKeys {
+		rows, err := dbMan.query(fk.relation, dbMan.orphansQuery(fk))
+		if err != nil {
+			dbMan.t.Fatalf("could not check referential integrity of %s: %+v", fk, err)
+		}
+		orphans, err := scanRows(rows)
+		if err != nil {
+			dbMan.t.Fatalf("could not check referential integrity of %s: %+v", fk, err)
+		}
+		if len(orphans) == 0 {
+			continue
+		}
+
+		values := make([]interface{}, len(orphans))
+		for i, o := range orphans {
+			values[i] = o["orphan"]
+		}
+		dbMan.t.Errorf("%s: %d records reference nonexistent parents: %v", fk, len(orphans), values)
+	}
+}
+
+// orphansQuery returns the query for the values of the given foreign key
+// without a matching parent record
+func (dbMan *dbManager) orphansQuery(fk foreignKey) sq.SelectBuilder {
+	child := "c." + dbMan.quoteColumn(fk.column)
+	parent := "p." + dbMan.quoteColumn(fk.parentColumn)
+
+	return dbMan.queryBuilder.
+		Select(child + " AS orphan").
+		From(dbMan.quoteIdent(fk.relation) + " AS c").
+		LeftJoin(fmt.Sprintf("%s AS p ON %s = %s", dbMan.quoteIdent(fk.parentRelation), child, parent)).
+		Where(fmt.Sprintf("%s IS NOT NULL AND %s IS NULL", child, parent))
+}
+
+// SeedOrdered creates a record (with its default values) for each of the
+// given relations, creating parents before their children as declared with
+// WithForeignKey. Otherwise, the given order is kept.
+// Self references are ignored, but the test fails on other relationship cycles.
+func (dbMan *dbManager) SeedOrdered(relations ...string) {
+	deps := make(map[string][]string, len(relations))
+	for _, fk := range dbMan.foreignKeys {
+		if fk.relation != fk.parentRelation {
+			deps[fk.relation] = append(deps[fk.relation], fk.parentRelation)
+		}
+	}
+
+	order, err := topoSort(relations, deps)
+	if err != nil {
+		dbMan.t.Fatalf("Test setup failed: could not order relations %v: %+v", relations, err)
+	}
+	for _, r := range order {
+		dbMan.Create(r)
+	}
+}
@@ -0,0 +1,327 @@
+package dbmanager
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+// CreateScan creates a new record for the relation specified by `tableName`
+// and scans the `returning` columns of the inserted row into `dest`.
+// Passing RelationValuesOption overrides the default value set in the creator.
+func (dbMan *dbManager) CreateScan(
+	tableName string,
+	dest []interface{},
+	returning []string,
+	opts ...RelationValuesOption,
+) {
+	if len(dest) != len(returning) {
+		dbMan.t.Fatalf(
+			"Test setup failed: %d scan destinations given for %d returning columns of '%s'",
+			len(dest), len(returning), tableName,
+		)
+	}
+
+	values := dbMan.relationValues(tableName, opts...)
+	created, err := dbMan.insertRow(tableName, values, returning, dest)
+	if err != nil {
+		dbMan.t.Fatalf("Test setup failed: could not create test record for '%s': %+v", tableName, err)
+	}
+	if !created {
+		dbMan.t.Fatalf("Test setup failed: test record for '%s' was not created (conflicting record already exists)", tableName)
+	}
+}
+
+// CreateAndGet creates a new record for the relation specified by `tableName`
+// and reads it back, returning all of its columns (server defaults included).
+// The record is read back by matching the inserted values, so expression values
+// (e.g. `sq.Expr("CURRENT_TIMESTAMP")`) are not considered for finding it.
+func (dbMan *dbManager) CreateAndGet(
+	tableName string,
+	opts ...RelationValuesOption,
+) RelationValues {
+	values := dbMan.relationValues(tableName, opts...)
+	created, err := dbMan.insertRow(tableName, values, nil, nil)
+	if err != nil {
+		dbMan.t.Fatalf("Test setup failed: could not create test record for '%s': %+v", tableName, err)
+	}
+	if !created {
+		dbMan.t.Fatalf("Test setup failed: test record for '%s' was not created (conflicting record already exists)", tableName)
+	}
+
+	values, call := splitCallOptions(values)
+	where := RelationValues{}
+	for k, v := range values {
+		if _, ok := v.(sq.Sqlizer); !ok {
+			where[k] = v
+		}
+	}
+	if len(where) == 0 {
+		dbMan.t.Fatalf("Test setup failed: no plain values to read back the test record for '%s' with", tableName)
+	}
+
+	query := dbMan.selectBuilder(call.table(tableName), "*").Where(dbMan.where(where))
+	rows, err := dbMan.query(tableName, query)
+	if err != nil {
+		dbMan.t.Fatalf("Test setup failed: could not read back test record for '%s': %+v", tableName, err)
+	}
+	records, err := scanRows(rows)
+	if err != nil {
+		dbMan.t.Fatalf("Test setup failed: could not read back test record for '%s': %+v", tableName, err)
+	}
+	if len(records) != 1 {
+		dbMan.t.Fatalf(
+			"Test setup failed: could not uniquely identify the test record for '%s' (%d matching records)",
+			tableName, len(records),
+		)
+	}
+
+	return records[0]
+}
+
+// CreateReturning creates a new record for the relation specified by
+// `tableName` and returns its key.
+// Keys supplied by the client (e.g. with GenUUID) are returned as inserted.
+// Otherwise the key is read back as the dialect's InsertedKey says (e.g. with
+// RETURNING on postgresql, the auto-increment id on mysql or OUTPUT on sql
+// server).
+func (dbMan *dbManager) CreateReturning(
+	tableName string,
+	opts ...RelationValuesOption,
+) interface{} {
+	pk, err := dbMan.singlePrimaryKey(tableName)
+	if err != nil {
+		dbMan.t.Fatalf("Test setup failed: could not create test record for '%s': %+v", tableName, err)
+	}
+
+	key := dbMan.createKey(tableName, opts...)
+	return key[pk]
+}
+
+// createKey creates a new record for the relation specified by `tableName`,
+// returning its primary key values
+func (dbMan *dbManager) createKey(tableName string, opts ...RelationValuesOption) RelationValues {
+	values := dbMan.relationValues(tableName, opts...)
+	key, created, err := dbMan.insertKey(tableName, values)
+	if err != nil {
+		dbMan.t.Fatalf("Test setup failed: could not create test record for '%s': %+v", tableName, err)
+	}
+	if !created {
+		dbMan.t.Fatalf("Test setup failed: test record for '%s' was not created (conflicting record already exists)", tableName)
+	}
+
+	return key
+}
+
+// insertKey inserts the given values returning the inserted row primary key
+// values. It returns false if the row was skipped due to a conflict.
+func (dbMan *dbManager) insertKey(tableName string, values RelationValues) (RelationValues, bool, error) {
+	pk := dbMan.primaryKey(tableName)
+	if key, ok := clientKey(values, pk); ok {
+		created, err := dbMan.insertWithKey(tableName, values, key)
+		return key, created, err
+	}
+	if dbMan.isView(tableName) {
+		return nil, false, fmt.Errorf("the key of records created through view '%s' must be supplied", tableName)
+	}
+
+	switch dbMan.dialect.InsertedKey() {
+	case KeyLastInsertID:
+		if len(pk) != 1 {
+			return nil, false, fmt.Errorf("relation '%s' has a composite primary key (%v)", tableName, pk)
+		}
+		id, created, err := dbMan.insertLastID(tableName, values)
+		return RelationValues{pk[0]: id}, created, err
+	case KeyOutput:
+		return dbMan.insertOutput(tableName, values, pk)
+	}
+
+	keyValues := make([]interface{}, len(pk))
+	dest := make([]interface{}, len(pk))
+	for i := range keyValues {
+		dest[i] = &keyValues[i]
+	}
+	created, err := dbMan.insertRow(tableName, values, pk, dest)
+
+	key := make(RelationValues, len(pk))
+	for i, c := range pk {
+		key[c] = keyValues[i]
+	}
+	return key, created, err
+}
+
+// clientKey returns the primary key values supplied in values, if all of
+// them are (expressions such as `DEFAULT` are left for the db to evaluate)
+func clientKey(values RelationValues, pk []string) (RelationValues, bool) {
+	key := make(RelationValues, len(pk))
+	for _, c := range pk {
+		v, ok := values[c]
+		if !ok {
+			return nil, false
+		}
+		if _, isExpr := v.(sq.Sqlizer); isExpr {
+			return nil, false
+		}
+		key[c] = v
+	}
+	return key, true
+}
+
+// insertWithKey inserts the given values, whose key is already known.
+// When the created records are being tracked, the key (if any) is recorded for
+// cleanup.
+// It returns false if the row was skipped due to a conflict.
+func (dbMan *dbManager) insertWithKey(tableName string, values RelationValues, key RelationValues) (bool, error) {
+	res, err := dbMan.exec(tableName, dbMan.insert(tableName, values))
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil || n == 0 {
+		return false, err
+	}
+
+	if dbMan.tracker != nil && key != nil {
+		dbMan.tracker.track(dbMan.db, tableName, key)
+	}
+	return true, nil
+}
+
+// insertLastID inserts the given values returning the auto-increment id
+// generated for the inserted row.
+// When the created records are being tracked, the id is recorded for cleanup.
+// It returns false if the row was skipped due to a conflict.
+func (dbMan *dbManager) insertLastID(tableName string, values RelationValues) (interface{}, bool, error) {
+	pk, err := dbMan.singlePrimaryKey(tableName)
+	if err != nil {
+		return nil, false, err
+	}
+
+	res, err := dbMan.exec(tableName, dbMan.insert(tableName, values))
+	if err != nil {
+		return nil, false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil || n == 0 {
+		return nil, false, err
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, false, err
+	}
+	if id == 0 {
+		return nil, false, fmt.Errorf("no auto-increment id generated for '%s'", tableName)
+	}
+
+	if dbMan.tracker != nil {
+		dbMan.tracker.track(dbMan.db, tableName, RelationValues{pk: id})
+	}
+	return id, true, nil
+}
+
+// insertOutput inserts the given values returning the inserted row primary
+// key values with an `OUTPUT INSERTED` clause.
+// When the created records are being tracked, the key is recorded for cleanup.
+// It returns false if the row was skipped due to a conflict.
+func (dbMan *dbManager) insertOutput(tableName string, values RelationValues, pk []string) (RelationValues, bool, error) {
+	query := outputInsert{query: dbMan.insert(tableName, values), output: dbMan.quoteColumns(pk)}
+	key, err := dbMan.scanKey(tableName, query, pk)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	if dbMan.tracker != nil {
+		dbMan.tracker.track(dbMan.db, tableName, key)
+	}
+	return key, true, nil
+}
+
+// insertRow inserts the given values, scanning the `returning` columns of the
+// inserted row into `dest`.
+// When the created records are being tracked, the inserted row key is recorded
+// for cleanup. It returns false if the row was skipped due to a conflict.
+func (dbMan *dbManager) insertRow(
+	tableName string,
+	values RelationValues,
+	returning []string,
+	dest []interface{},
+) (bool, error) {
+	if !dbMan.supportsReturning(tableName) {
+		if len(returning) > 0 {
+			return false, dbMan.errReturningNotSupported(tableName)
+		}
+		if dbMan.tracker == nil {
+			return dbMan.insertWithKey(tableName, values, nil)
+		}
+		_, created, err := dbMan.insertKey(tableName, values)
+		return created, err
+	}
+
+	pk := dbMan.primaryKey(tableName)
+	key := make([]interface{}, len(pk))
+	if dbMan.tracker != nil {
+		returning = append(returning[:len(returning):len(returning)], pk...)
+		dest = dest[:len(dest):len(dest)]
+		for i := range key {
+			dest = append(dest, &key[i])
+		}
+	}
+
+	query := dbMan.insert(tableName, values)
+	if len(returning) == 0 {
+		res, err := dbMan.exec(tableName, query)
+		if err != nil {
+			return false, err
+		}
+		n, err := res.RowsAffected()
+		return n > 0, err
+	}
+
+	query = query.Suffix("RETURNING " + strings.Join(dbMan.quoteColumns(returning), ", "))
+	err := dbMan.scanRow(tableName, query, dest...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if dbMan.tracker != nil {
+		trackedKey := make(RelationValues, len(pk))
+		for i, c := range pk {
+			trackedKey[c] = key[i]
+		}
+		dbMan.tracker.track(dbMan.db, tableName, trackedKey)
+	}
+	return true, nil
+}
+
+// insertRecord inserts the given values returning the whole inserted row.
+// When the created records are being tracked, the inserted row key is recorded
+// for cleanup. It returns nil if the row was skipped due to a conflict.
+func (dbMan *dbManager) insertRecord(tableName string, values RelationValues) (RelationValues, error) {
+	if !dbMan.supportsReturning(tableName) {
+		return nil, dbMan.errReturningNotSupported(tableName)
+	}
+
+	rows, err := dbMan.query(tableName, dbMan.insert(tableName, values).Suffix("RETURNING *"))
+	if err != nil {
+		return nil, err
+	}
+	records, err := scanRows(rows)
+	if err != nil || len(records) == 0 {
+		return nil, err
+	}
+
+	if dbMan.tracker != nil {
+		dbMan.tracker.track(dbMan.db, tableName, dbMan.recordKey(tableName, records[0]))
+	}
+	return records[0], nil
+}
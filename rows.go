@@ -0,0 +1,67 @@
+package dbmanager
+
+import (
+	"database/sql"
+	"strings"
+)
+
+// scanRows reads all the given rows into RelationValues keyed by column name.
+// The rows are always closed.
+func scanRows(rows *sql.Rows) ([]RelationValues, error) {
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	var records []RelationValues
+	for rows.Next() {
+		dest := make([]interface{}, len(columns))
+		ptrs := make([]interface{}, len(columns))
+		for i := range dest {
+			ptrs[i] = &dest[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, err
+		}
+
+		record := make(RelationValues, len(columns))
+		for i, c := range columns {
+			record[c] = dest[i]
+		}
+		records = append(records, record)
+	}
+
+	return records, rows.Err()
+}
+
+// columnValue returns the value of the given (possibly quoted or qualified)
+// column in record, whose keys are the column names as reported by the db
+// (e.g. lower cased on postgresql for unquoted identifiers)
+func columnValue(record RelationValues, column string) interface{} {
+	if k, ok := columnKey(record, column); ok {
+		return record[k]
+	}
+	return nil
+}
+
+// columnKey returns the key of the given (possibly quoted or qualified) column
+// in record, whose keys are the column names as reported by the db
+func columnKey(record RelationValues, column string) (string, bool) {
+	if _, ok := record[column]; ok {
+		return column, true
+	}
+
+	segments := splitIdent(column)
+	name := unquoteIdent(segments[len(segments)-1])
+	if _, ok := record[name]; ok {
+		return name, true
+	}
+	for k := range record {
+		if strings.EqualFold(k, name) {
+			return k, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,25 @@
+package dbmanager
+
+import "testing"
+
+func TestColumnValue(t *testing.T) {
+	record := RelationValues{"createdat": 1, "Order": 2}
+	cases := []struct {
+		column string
+		exp    interface{}
+	}{
+		{column: "createdAt", exp: 1},
+		{column: "createdat", exp: 1},
+		{column: `"Order"`, exp: 2},
+		{column: "o.Order", exp: 2},
+		{column: "missing", exp: nil},
+	}
+
+	for _, c := range cases {
+		t.Run(c.column, func(t *testing.T) {
+			if got := columnValue(record, c.column); got != c.exp {
+				t.Errorf("expected %v, got %v", c.exp, got)
+			}
+		})
+	}
+}
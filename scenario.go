@@ -0,0 +1,65 @@
+package dbmanager
+
+import (
+	"database/sql"
+	"testing"
+)
+
+// ScenarioRunner runs an arrange-act-assert test scenario around a DBManager
+// tracking the records it creates (see Setup), removing them once the test
+// finishes.
+type ScenarioRunner struct {
+	t             *testing.T
+	db            *sql.DB
+	defaultValues map[string]RelationValues
+	opts          []Option
+
+	given []func(DBManager)
+	when  []func()
+	then  []func(DBManager)
+}
+
+// Scenario returns a ScenarioRunner for a DBManager created with the given
+// db, default values and options
+func Scenario(
+	t *testing.T,
+	db *sql.DB,
+	defaultValues map[string]RelationValues,
+	opts ...Option,
+) *ScenarioRunner {
+	return &ScenarioRunner{t: t, db: db, defaultValues: defaultValues, opts: opts}
+}
+
+// Given adds a step seeding the records the scenario starts from
+func (s *ScenarioRunner) Given(fn func(DBManager)) *ScenarioRunner {
+	s.given = append(s.given, fn)
+	return s
+}
+
+// When adds a step running the code under test
+func (s *ScenarioRunner) When(fn func()) *ScenarioRunner {
+	s.when = append(s.when, fn)
+	return s
+}
+
+// Then adds a step asserting the resulting db state
+func (s *ScenarioRunner) Then(fn func(DBManager)) *ScenarioRunner {
+	s.then = append(s.then, fn)
+	return s
+}
+
+// Run runs the Given, When and Then steps (in the order they were added
+// within each kind). The records created by the scenario are deleted once the
+// test finishes (see Setup).
+func (s *ScenarioRunner) Run() {
+	dbMan := Setup(s.t, s.db, s.defaultValues, nil, s.opts...)
+	for _, fn := range s.given {
+		fn(dbMan)
+	}
+	for _, fn := range s.when {
+		fn()
+	}
+	for _, fn := range s.then {
+		fn(dbMan)
+	}
+}
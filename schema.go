@@ -0,0 +1,253 @@
+package dbmanager
+
+import (
+	"database/sql"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+// tableSchema represents the schema of a table looked up from the information
+// schema
+type tableSchema struct {
+	// columns in ordinal position order
+	columns    []columnSchema
+	primaryKey []string
+}
+
+// columnSchema represents the schema of a table column
+type columnSchema struct {
+	name       string
+	dataType   string
+	nullable   bool
+	hasDefault bool
+}
+
+// hasColumn returns whether the table has the given column
+func (ts *tableSchema) hasColumn(name string) bool {
+	for _, c := range ts.columns {
+		if c.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// hasColumnFold returns whether the table has the given column, ignoring case
+func (ts *tableSchema) hasColumnFold(name string) bool {
+	for _, c := range ts.columns {
+		if strings.EqualFold(c.name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasKeyColumn returns whether the table has the column referenced by the
+// given values key, interpreting it as quoteColumn does: quoted keys are
+// matched exactly, as are all keys when quoting with a configured style, and
+// plain keys as the dialect would interpret them unquoted
+func (dbMan *dbManager) hasKeyColumn(ts *tableSchema, key string) bool {
+	switch {
+	case isQuoted(key):
+		return ts.hasColumn(unquoteIdent(key))
+	case dbMan.quoteStyle != QuoteNone || !isPlainIdent(key):
+		return ts.hasColumn(key)
+	case dbMan.dialect == DialectPostgres:
+		return ts.hasColumn(foldIdent(key))
+	default:
+		return ts.hasColumnFold(key)
+	}
+}
+
+// InvalidateSchemaCache drops the cached schema of the given tables (or of all
+// tables if none is given), so it is looked up again on its next use
+func (dbMan *dbManager) InvalidateSchemaCache(tableNames ...string) {
+	if dbMan.schemaCache == nil {
+		return
+	}
+	if len(tableNames) == 0 {
+		for t := range dbMan.schemaCache {
+			delete(dbMan.schemaCache, t)
+		}
+		return
+	}
+	for _, t := range tableNames {
+		delete(dbMan.schemaCache, t)
+	}
+}
+
+// existingColumnsValues returns a copy of the given values without the ones
+// for columns not present in the given table
+func (dbMan *dbManager) existingColumnsValues(tableName string, values RelationValues) RelationValues {
+	schema := dbMan.tableSchema(tableName)
+	existing := make(RelationValues, len(values))
+	for k, v := range values {
+		if dbMan.hasKeyColumn(schema, k) {
+			existing[k] = v
+		}
+	}
+	return existing
+}
+
+// tableSchema returns the schema of the given table, looking it up from the
+// information schema unless it was already cached
+func (dbMan *dbManager) tableSchema(tableName string) *tableSchema {
+	if schema, ok := dbMan.schemaCache[tableName]; ok {
+		return schema
+	}
+
+	rows, err := dbMan.query(tableName, dbMan.columnsQuery(tableName))
+	if err != nil {
+		dbMan.t.Fatalf("could not look up the schema of '%s': %+v", tableName, err)
+	}
+	columns, err := scanColumns(rows)
+	if err != nil {
+		dbMan.t.Fatalf("could not look up the schema of '%s': %+v", tableName, err)
+	}
+	if len(columns) == 0 {
+		dbMan.t.Fatalf("could not look up the schema of '%s': table not found", tableName)
+	}
+
+	rows, err = dbMan.query(tableName, dbMan.primaryKeyQuery(tableName))
+	if err != nil {
+		dbMan.t.Fatalf("could not look up the schema of '%s': %+v", tableName, err)
+	}
+	pk, err := scanStrings(rows)
+	if err != nil {
+		dbMan.t.Fatalf("could not look up the schema of '%s': %+v", tableName, err)
+	}
+
+	schema := &tableSchema{columns: columns, primaryKey: pk}
+	if dbMan.schemaCache != nil {
+		dbMan.schemaCache[tableName] = schema
+	}
+	return schema
+}
+
+// columnsQuery returns the information schema query for the columns of the
+// given (optionally schema qualified) table
+func (dbMan *dbManager) columnsQuery(tableName string) sq.SelectBuilder {
+	schema, tableName := dbMan.splitSchema(tableName)
+	return dbMan.queryBuilder.
+		Select(
+			"column_name",
+			"data_type",
+			"CASE WHEN is_nullable = 'YES' THEN 1 ELSE 0 END",
+			dbMan.dialect.columnHasDefault(),
+		).
+		From("information_schema.columns").
+		Where(sq.Eq{"table_name": tableName}).
+		Where(sq.Expr("table_schema = ?", schema)).
+		OrderBy("ordinal_position")
+}
+
+// primaryKeyQuery returns the information schema query for the primary key
+// columns of the given (optionally schema qualified) table
+func (dbMan *dbManager) primaryKeyQuery(tableName string) sq.SelectBuilder {
+	schema, tableName := dbMan.splitSchema(tableName)
+	return dbMan.queryBuilder.
+		Select("kcu.column_name").
+		From("information_schema.table_constraints AS tc").
+		Join("information_schema.key_column_usage AS kcu ON " +
+			"kcu.constraint_name = tc.constraint_name AND " +
+			"kcu.table_schema = tc.table_schema AND " +
+			"kcu.table_name = tc.table_name").
+		Where(sq.Eq{"tc.constraint_type": "PRIMARY KEY", "tc.table_name": tableName}).
+		Where(sq.Expr("tc.table_schema = ?", schema)).
+		OrderBy("kcu.ordinal_position")
+}
+
+// splitSchema splits the schema from qualified table names, defaulting to the
+// current schema for unqualified ones
+func (dbMan *dbManager) splitSchema(tableName string) (interface{}, string) {
+	if i := strings.LastIndex(tableName, "."); i >= 0 {
+		return tableName[:i], tableName[i+1:]
+	}
+	return dbMan.dialect.currentSchema(), tableName
+}
+
+func scanColumns(rows *sql.Rows) ([]columnSchema, error) {
+	defer rows.Close()
+
+	var columns []columnSchema
+	for rows.Next() {
+		var c columnSchema
+		if err := rows.Scan(&c.name, &c.dataType, &c.nullable, &c.hasDefault); err != nil {
+			return nil, err
+		}
+		columns = append(columns, c)
+	}
+	return columns, rows.Err()
+}
+
+func scanStrings(rows *sql.Rows) ([]string, error) {
+	defer rows.Close()
+
+	var values []string
+	for rows.Next() {
+		var v string
+		if err := rows.Scan(&v); err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+	return values, rows.Err()
+}
+
+// GenerateDefaults returns default values for the given tables, built from
+// their schema, to use as a starting point for the defaults map given to New.
+// Nullable columns and columns with a default (including identity and
+// auto-increment columns) are skipped. Other columns get
+// a placeholder value for their type (e.g. "" for text and 0 for numbers),
+// with timestamps generated from the current time; columns of other types are
+// left out for the values to be filled in by hand.
+func (dbMan *dbManager) GenerateDefaults(tableNames ...string) map[string]RelationValues {
+	defaults := make(map[string]RelationValues, len(tableNames))
+	for _, tableName := range tableNames {
+		values := make(RelationValues)
+		for _, c := range dbMan.tableSchema(tableName).columns {
+			if c.nullable || c.hasDefault {
+				continue
+			}
+			if v, ok := dbMan.placeholderValue(c.dataType); ok {
+				values[c.name] = v
+			}
+		}
+		defaults[tableName] = values
+	}
+	return defaults
+}
+
+// numericTypes are the information schema data types of numeric columns
+var numericTypes = map[string]bool{
+	"smallint":         true,
+	"integer":          true,
+	"int":              true,
+	"bigint":           true,
+	"tinyint":          true,
+	"mediumint":        true,
+	"numeric":          true,
+	"decimal":          true,
+	"real":             true,
+	"float":            true,
+	"double":           true,
+	"double precision": true,
+}
+
+// placeholderValue returns a placeholder value for columns of the given
+// information schema data type, if the type is known
+func (dbMan *dbManager) placeholderValue(dataType string) (interface{}, bool) {
+	dataType = strings.ToLower(dataType)
+	switch {
+	case dataType == "boolean" || dataType == "bool" || dataType == "bit":
+		return false, true
+	case strings.HasPrefix(dataType, "timestamp") || strings.HasPrefix(dataType, "datetime") || dataType == "date":
+		return GenNow, true
+	case numericTypes[dataType]:
+		return 0, true
+	case strings.Contains(dataType, "char"), strings.HasSuffix(dataType, "text"):
+		return "", true
+	}
+	return nil, false
+}
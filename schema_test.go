@@ -0,0 +1,31 @@
+package dbmanager
+
+import "testing"
+
+func TestHasKeyColumn(t *testing.T) {
+	schema := &tableSchema{columns: []columnSchema{{name: "email"}, {name: "Order"}}}
+	cases := []struct {
+		title   string
+		dialect Dialect
+		key     string
+		exp     bool
+	}{
+		{title: "plain key", dialect: DialectPostgres, key: "email", exp: true},
+		{title: "plain key folded on postgresql", dialect: DialectPostgres, key: "Email", exp: true},
+		{title: "quoted key", dialect: DialectPostgres, key: `"Order"`, exp: true},
+		{title: "quoted key of a different case", dialect: DialectPostgres, key: `"EMAIL"`, exp: false},
+		{title: "plain key not folding to the column", dialect: DialectPostgres, key: "Order", exp: false},
+		{title: "plain key of a different case on mysql", dialect: DialectMySQL, key: "ORDER", exp: true},
+		{title: "backtick quoted key", dialect: DialectMySQL, key: "`Order`", exp: true},
+		{title: "missing column", dialect: DialectPostgres, key: "name", exp: false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			dbMan := New(nil, t, nil, WithDialect(c.dialect)).(*dbManager)
+			if got := dbMan.hasKeyColumn(schema, c.key); got != c.exp {
+				t.Errorf("expected %t, got %t", c.exp, got)
+			}
+		})
+	}
+}
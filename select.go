@@ -0,0 +1,45 @@
+package dbmanager
+
+import (
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+// CreateFromSelect creates a record for the relation specified by `tableName`
+// for each row returned by `selectBuilder`, using a single
+// `INSERT INTO ... SELECT` statement. The selected columns are inserted into
+// `columns`, in order.
+// Default values aren't used, as all values come from the select.
+func (dbMan *dbManager) CreateFromSelect(
+	tableName string,
+	columns []string,
+	selectBuilder sq.SelectBuilder,
+) {
+	// the select placeholders are replaced along with the insert ones
+	query := dbMan.insertBuilder(tableName).
+		Columns(dbMan.quoteColumns(columns)...).
+		Select(selectBuilder.PlaceholderFormat(sq.Question))
+	query = dbMan.handleConflicts(tableName, callOptions{}, query)
+
+	if dbMan.tracker == nil || !dbMan.supportsReturning(tableName) {
+		if _, err := dbMan.exec(tableName, query); err != nil {
+			dbMan.t.Fatalf("Test setup failed: could not create test records for '%s': %+v", tableName, err)
+		}
+		return
+	}
+
+	pk := dbMan.primaryKey(tableName)
+	query = query.Suffix("RETURNING " + strings.Join(dbMan.quoteColumns(pk), ", "))
+	rows, err := dbMan.query(tableName, query)
+	if err != nil {
+		dbMan.t.Fatalf("Test setup failed: could not create test records for '%s': %+v", tableName, err)
+	}
+	keys, err := scanRows(rows)
+	if err != nil {
+		dbMan.t.Fatalf("Test setup failed: could not create test records for '%s': %+v", tableName, err)
+	}
+	for _, key := range keys {
+		dbMan.tracker.track(dbMan.db, tableName, key)
+	}
+}
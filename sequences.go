@@ -0,0 +1,62 @@
+package dbmanager
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+// ResetSequence restarts the sequence generating the values of `column` of the
+// relation specified by `tableName`, so the next generated value is 1.
+// On mysql the table auto-increment counter is reset instead.
+func (dbMan *dbManager) ResetSequence(tableName string, column string) {
+	reset, err := dbMan.resetSequence(tableName, column)
+	if err != nil {
+		dbMan.t.Fatalf("Test setup failed: could not reset the sequence of '%s.%s': %+v", tableName, column, err)
+	}
+	if !reset {
+		dbMan.t.Fatalf("Test setup failed: no sequence generates the values of '%s.%s'", tableName, column)
+	}
+}
+
+// ResetAllSequences restarts the sequences generating the primary keys of all
+// the relations with default values.
+// Relations with composite or non generated primary keys are skipped.
+func (dbMan *dbManager) ResetAllSequences() {
+	relations := make([]string, 0, len(dbMan.defaultRelationValues))
+	for r := range dbMan.defaultRelationValues {
+		relations = append(relations, r)
+	}
+	sort.Strings(relations)
+
+	for _, r := range relations {
+		pk, err := dbMan.singlePrimaryKey(r)
+		if err != nil {
+			continue
+		}
+		if _, err := dbMan.resetSequence(r, pk); err != nil {
+			dbMan.t.Fatalf("Test setup failed: could not reset the sequence of '%s.%s': %+v", r, pk, err)
+		}
+	}
+}
+
+// resetSequence restarts the sequence generating the values of the given
+// column, returning false if there's no such sequence
+func (dbMan *dbManager) resetSequence(tableName string, column string) (bool, error) {
+	switch dbMan.dialect {
+	case DialectMySQL:
+		query := sq.Expr(fmt.Sprintf("ALTER TABLE %s AUTO_INCREMENT = 1", dbMan.quoteIdent(tableName)))
+		_, err := dbMan.exec(tableName, query)
+		return err == nil, err
+	case DialectSQLServer:
+		return false, fmt.Errorf("resetting sequences is not supported by %s", dbMan.dialect)
+	default:
+		var value sql.NullInt64
+		query := dbMan.queryBuilder.Select().
+			Column(sq.Expr("setval(pg_get_serial_sequence(?, ?), 1, false)", dbMan.quoteIdent(tableName), column))
+		err := dbMan.scanRow(tableName, query, &value)
+		return value.Valid, err
+	}
+}
@@ -0,0 +1,221 @@
+package dbmanager
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// AssertTableEquals asserts that the records of the relation specified by
+// `tableName` are exactly the `expected` ones (in any order), ignoring the
+// given columns (e.g. generated ids and timestamps).
+// Missing, unexpected and differing records (matched by primary key, when it
+// isn't ignored) are all reported at once.
+func (dbMan *dbManager) AssertTableEquals(
+	tableName string,
+	expected []RelationValues,
+	ignoreColumns ...string,
+) {
+	rows, err := dbMan.query(tableName, dbMan.selectBuilder(tableName, "*"))
+	if err != nil {
+		dbMan.t.Fatalf("could not read records for '%s': %+v", tableName, err)
+	}
+	actual, err := scanRows(rows)
+	if err != nil {
+		dbMan.t.Fatalf("could not read records for '%s': %+v", tableName, err)
+	}
+
+	// the column names given by the caller are resolved to the ones reported
+	// by the db (e.g. lower cased on postgresql)
+	pk := dbMan.primaryKey(tableName)
+	if len(actual) > 0 {
+		expected = withColumnKeys(expected, actual[0])
+		ignoreColumns = columnKeys(ignoreColumns, actual[0])
+		pk = columnKeys(pk, actual[0])
+	}
+	expected = withoutColumns(expected, ignoreColumns)
+	actual = withoutColumns(actual, ignoreColumns)
+
+	missing, unexpected := unmatchedRecords(expected, actual)
+
+	var report []string
+	for i := 0; i < len(missing); i++ {
+		j := findByKey(unexpected, missing[i], pk)
+		if j < 0 {
+			continue
+		}
+		report = append(report, fmt.Sprintf("\tdiffering %v: %s", keyOf(missing[i], pk), recordDiff(missing[i], unexpected[j])))
+		missing = append(missing[:i], missing[i+1:]...)
+		unexpected = append(unexpected[:j], unexpected[j+1:]...)
+		i--
+	}
+	for _, r := range missing {
+		report = append(report, fmt.Sprintf("\tmissing: %v", r))
+	}
+	for _, r := range unexpected {
+		report = append(report, fmt.Sprintf("\tunexpected: %v", r))
+	}
+
+	if len(report) > 0 {
+		dbMan.t.Errorf(
+			"records for '%s' don't match the expected ones (%d expected, %d found):\n%s",
+			tableName, len(expected), len(actual), strings.Join(report, "\n"),
+		)
+	}
+}
+
+// withoutColumns returns copies of the given records without the given
+// columns
+func withoutColumns(records []RelationValues, columns []string) []RelationValues {
+	ignored := make(map[string]struct{}, len(columns))
+	for _, c := range columns {
+		ignored[c] = struct{}{}
+	}
+
+	projected := make([]RelationValues, len(records))
+	for i, r := range records {
+		projected[i] = make(RelationValues, len(r))
+		for k, v := range r {
+			if _, ok := ignored[k]; !ok {
+				projected[i][k] = v
+			}
+		}
+	}
+	return projected
+}
+
+// columnKeys returns the keys of the given columns in `reference` (see
+// columnKey), leaving the columns it doesn't have as given
+func columnKeys(columns []string, reference RelationValues) []string {
+	keys := make([]string, len(columns))
+	for i, c := range columns {
+		keys[i] = c
+		if k, ok := columnKey(reference, c); ok {
+			keys[i] = k
+		}
+	}
+	return keys
+}
+
+// withColumnKeys returns copies of the given records keyed by the keys of
+// their columns in `reference` (see columnKey)
+func withColumnKeys(records []RelationValues, reference RelationValues) []RelationValues {
+	keyed := make([]RelationValues, len(records))
+	for i, r := range records {
+		keyed[i] = make(RelationValues, len(r))
+		for k, v := range r {
+			if rk, ok := columnKey(reference, k); ok {
+				k = rk
+			}
+			keyed[i][k] = v
+		}
+	}
+	return keyed
+}
+
+// unmatchedRecords pairs equal expected and actual records, returning the
+// ones left without a pair
+func unmatchedRecords(expected, actual []RelationValues) ([]RelationValues, []RelationValues) {
+	matched := make([]bool, len(actual))
+	var missing []RelationValues
+	for _, e := range expected {
+		found := false
+		for j, a := range actual {
+			if !matched[j] && recordsEqual(e, a) {
+				matched[j], found = true, true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, e)
+		}
+	}
+
+	var unexpected []RelationValues
+	for j, a := range actual {
+		if !matched[j] {
+			unexpected = append(unexpected, a)
+		}
+	}
+	return missing, unexpected
+}
+
+// recordsEqual returns whether the given records have the same columns with
+// equal values
+func recordsEqual(a, b RelationValues) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		bv, ok := b[k]
+		if !ok || !valuesEqual(v, bv) {
+			return false
+		}
+	}
+	return true
+}
+
+// findByKey returns the index of the record with the same primary key values
+// as `record`, or -1 if there's none (or the key columns are missing)
+func findByKey(records []RelationValues, record RelationValues, pk []string) int {
+	for _, c := range pk {
+		if _, ok := record[c]; !ok {
+			return -1
+		}
+	}
+
+	for i, r := range records {
+		matches := true
+		for _, c := range pk {
+			v, ok := r[c]
+			if !ok || !valuesEqual(v, record[c]) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			return i
+		}
+	}
+	return -1
+}
+
+func keyOf(record RelationValues, pk []string) RelationValues {
+	key := make(RelationValues, len(pk))
+	for _, c := range pk {
+		key[c] = record[c]
+	}
+	return key
+}
+
+// recordDiff describes the columns whose values differ between the expected
+// and actual records
+func recordDiff(expected, actual RelationValues) string {
+	columns := make(map[string]struct{}, len(expected))
+	for k := range expected {
+		columns[k] = struct{}{}
+	}
+	for k := range actual {
+		columns[k] = struct{}{}
+	}
+	sorted := make([]string, 0, len(columns))
+	for c := range columns {
+		sorted = append(sorted, c)
+	}
+	sort.Strings(sorted)
+
+	var diffs []string
+	for _, c := range sorted {
+		e, eok := expected[c]
+		a, aok := actual[c]
+		switch {
+		case !eok:
+			diffs = append(diffs, fmt.Sprintf("%s: unexpected %v", c, a))
+		case !aok:
+			diffs = append(diffs, fmt.Sprintf("%s: missing (expected %v)", c, e))
+		case !valuesEqual(e, a):
+			diffs = append(diffs, fmt.Sprintf("%s: expected %v, got %v", c, e, a))
+		}
+	}
+	return strings.Join(diffs, ", ")
+}
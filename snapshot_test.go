@@ -0,0 +1,34 @@
+package dbmanager
+
+import "testing"
+
+func TestUnmatchedRecords(t *testing.T) {
+	actual := []RelationValues{{"id": int64(1), "zip": []byte("01234"), "createdat": "now"}}
+	cases := []struct {
+		title    string
+		expected []RelationValues
+		exp      int
+	}{
+		{
+			title:    "matches ignoring columns by the db reported name",
+			expected: []RelationValues{{"id": 1, "zip": "01234", "createdAt": "then"}},
+			exp:      0,
+		},
+		{
+			title:    "doesn't match numeric looking text with a different padding",
+			expected: []RelationValues{{"id": 1, "zip": "1234"}},
+			exp:      1,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			ignore := columnKeys([]string{"createdAt"}, actual[0])
+			expected := withoutColumns(withColumnKeys(c.expected, actual[0]), ignore)
+			missing, unexpected := unmatchedRecords(expected, withoutColumns(actual, ignore))
+			if len(missing) != c.exp || len(unexpected) != c.exp {
+				t.Errorf("expected %d unmatched records, got missing %v and unexpected %v", c.exp, missing, unexpected)
+			}
+		})
+	}
+}
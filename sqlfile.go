@@ -0,0 +1,162 @@
+package dbmanager
+
+import (
+	"io/ioutil"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+// RunSQLFile runs each of the statements of the SQL file at `path` against the
+// db, in order.
+// Statements are split on `;`, ignoring the ones inside quoted strings and
+// identifiers, dollar-quoted blocks and comments.
+func (dbMan *dbManager) RunSQLFile(path string) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		dbMan.t.Fatalf("Test setup failed: could not read SQL file '%s': %+v", path, err)
+	}
+
+	for i, stmt := range splitStatements(string(content)) {
+		if _, err := dbMan.exec(path, sq.Expr(stmt)); err != nil {
+			dbMan.t.Fatalf("Test setup failed: could not run statement %d of '%s': %+v", i+1, path, err)
+		}
+	}
+}
+
+// splitStatements splits the given SQL script into its statements, dropping
+// the empty ones
+func splitStatements(script string) []string {
+	var (
+		stmts []string
+		start int
+		// hasCode tells whether the current statement has anything other than
+		// whitespace and comments
+		hasCode bool
+	)
+
+	appendStmt := func(end int) {
+		if hasCode {
+			stmts = append(stmts, strings.TrimSpace(script[start:end]))
+		}
+		start = end + 1
+		hasCode = false
+	}
+
+	for i := 0; i < len(script); i++ {
+		c := script[i]
+		switch {
+		case c == ';':
+			appendStmt(i)
+			continue
+		case c == '-' && strings.HasPrefix(script[i:], "--"):
+			i = skipLineComment(script, i)
+			continue
+		case c == '/' && strings.HasPrefix(script[i:], "/*"):
+			i = skipBlockComment(script, i)
+			continue
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			continue
+		}
+
+		hasCode = true
+		switch {
+		case c == '\'':
+			escapes := i > 0 && (script[i-1] == 'E' || script[i-1] == 'e') && !isIdentChar(script, i-2)
+			i = skipQuoted(script, i, '\'', escapes)
+		case c == '"':
+			i = skipQuoted(script, i, '"', false)
+		case c == '$':
+			if tag, ok := dollarQuoteTag(script, i); ok && !isIdentChar(script, i-1) {
+				i = skipDollarQuoted(script, i, tag)
+			}
+		}
+	}
+	appendStmt(len(script))
+
+	return stmts
+}
+
+// skipLineComment returns the index of the end of the line comment starting at i
+func skipLineComment(script string, i int) int {
+	if end := strings.IndexByte(script[i:], '\n'); end >= 0 {
+		return i + end
+	}
+	return len(script) - 1
+}
+
+// skipBlockComment returns the index of the end of the (possibly nested) block
+// comment starting at i
+func skipBlockComment(script string, i int) int {
+	depth := 0
+	for ; i < len(script); i++ {
+		switch {
+		case strings.HasPrefix(script[i:], "/*"):
+			depth++
+			i++
+		case strings.HasPrefix(script[i:], "*/"):
+			depth--
+			i++
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(script) - 1
+}
+
+// skipQuoted returns the index of the closing quote of the string or
+// identifier starting at i. Doubled quotes are part of the quoted text,
+// as are backslash escaped characters when `escapes` is set.
+func skipQuoted(script string, i int, quote byte, escapes bool) int {
+	for i++; i < len(script); i++ {
+		switch script[i] {
+		case '\\':
+			if escapes {
+				i++
+			}
+		case quote:
+			if i+1 < len(script) && script[i+1] == quote {
+				i++
+				continue
+			}
+			return i
+		}
+	}
+	return len(script) - 1
+}
+
+// dollarQuoteTag returns the dollar quote tag (e.g. `$$` or `$body$`) starting
+// at i, if any
+func dollarQuoteTag(script string, i int) (string, bool) {
+	for j := i + 1; j < len(script); j++ {
+		c := script[j]
+		switch {
+		case c == '$':
+			return script[i : j+1], true
+		case c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80:
+		case c >= '0' && c <= '9' && j > i+1:
+		default:
+			return "", false
+		}
+	}
+	return "", false
+}
+
+// skipDollarQuoted returns the index of the end of the closing tag of the
+// dollar-quoted block starting at i
+func skipDollarQuoted(script string, i int, tag string) int {
+	if end := strings.Index(script[i+len(tag):], tag); end >= 0 {
+		return i + len(tag) + end + len(tag) - 1
+	}
+	return len(script) - 1
+}
+
+// isIdentChar returns whether the character at i is part of an identifier
+func isIdentChar(script string, i int) bool {
+	if i < 0 || i >= len(script) {
+		return false
+	}
+	c := script[i]
+	return c == '_' || c == '$' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= 0x80
+}
@@ -0,0 +1,71 @@
+package dbmanager
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitStatements(t *testing.T) {
+	cases := []struct {
+		title  string
+		script string
+		exp    []string
+	}{
+		{
+			title:  "splits on semicolons dropping empty statements",
+			script: "INSERT INTO a VALUES (1);\n;\n  INSERT INTO b VALUES (2)  ",
+			exp:    []string{"INSERT INTO a VALUES (1)", "INSERT INTO b VALUES (2)"},
+		},
+		{
+			title:  "ignores semicolons in strings with doubled quotes",
+			script: "INSERT INTO a VALUES ('it''s; here');SELECT 1",
+			exp:    []string{"INSERT INTO a VALUES ('it''s; here')", "SELECT 1"},
+		},
+		{
+			title:  "ignores semicolons in quoted identifiers",
+			script: `SELECT "a;""b" FROM t;SELECT 1`,
+			exp:    []string{`SELECT "a;""b" FROM t`, "SELECT 1"},
+		},
+		{
+			title:  "handles backslash escapes in E-strings",
+			script: `SELECT E'it\'s; here';SELECT 'back\';SELECT 2`,
+			exp:    []string{`SELECT E'it\'s; here'`, `SELECT 'back\'`, "SELECT 2"},
+		},
+		{
+			title:  "ignores semicolons in dollar quoted bodies",
+			script: "CREATE FUNCTION f() RETURNS int AS $body$ SELECT 1; $$ x $$; $body$ LANGUAGE sql;SELECT $$a;b$$",
+			exp: []string{
+				"CREATE FUNCTION f() RETURNS int AS $body$ SELECT 1; $$ x $$; $body$ LANGUAGE sql",
+				"SELECT $$a;b$$",
+			},
+		},
+		{
+			title:  "doesn't take positional parameters as dollar quotes",
+			script: "PREPARE p AS SELECT $1;SELECT 'x$1;'",
+			exp:    []string{"PREPARE p AS SELECT $1", "SELECT 'x$1;'"},
+		},
+		{
+			title:  "ignores semicolons in line comments",
+			script: "-- first; statement\nSELECT 1; -- trailing;\nSELECT 2",
+			exp:    []string{"-- first; statement\nSELECT 1", "-- trailing;\nSELECT 2"},
+		},
+		{
+			title:  "ignores semicolons in nested block comments",
+			script: "SELECT /* a; /* nested; */ still; */ 1;SELECT 2",
+			exp:    []string{"SELECT /* a; /* nested; */ still; */ 1", "SELECT 2"},
+		},
+		{
+			title:  "drops statements made of comments only",
+			script: "SELECT 1;\n-- done;\n/* nothing; */",
+			exp:    []string{"SELECT 1"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.title, func(t *testing.T) {
+			if got := splitStatements(c.script); !reflect.DeepEqual(got, c.exp) {
+				t.Errorf("expected %q, got %q", c.exp, got)
+			}
+		})
+	}
+}
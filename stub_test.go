@@ -0,0 +1,123 @@
+package dbmanager
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"sync"
+	"testing"
+)
+
+// stubDriver is a database/sql driver (and connector) recording the
+// statements it runs, which affect a single row (unless set otherwise) and
+// return no rows
+type stubDriver struct {
+	mu      sync.Mutex
+	queries []string
+	// txQueries are the queries run within a transaction
+	txQueries []string
+	// rowsAffected are the rows affected by the following statements, in order
+	rowsAffected []int64
+	// execErr is returned by every statement execution, if set
+	execErr error
+}
+
+func (d *stubDriver) Open(string) (driver.Conn, error) {
+	return &stubConn{d: d}, nil
+}
+
+func (d *stubDriver) record(query string, inTx bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.queries = append(d.queries, query)
+	if inTx {
+		d.txQueries = append(d.txQueries, query)
+	}
+}
+
+// nextRowsAffected returns the rows affected by the next statement
+func (d *stubDriver) nextRowsAffected() int64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.rowsAffected) == 0 {
+		return 1
+	}
+	n := d.rowsAffected[0]
+	d.rowsAffected = d.rowsAffected[1:]
+	return n
+}
+
+type stubConn struct {
+	d    *stubDriver
+	inTx bool
+}
+
+func (c *stubConn) Prepare(query string) (driver.Stmt, error) {
+	return stubStmt{c: c, query: query}, nil
+}
+
+func (c *stubConn) Close() error { return nil }
+
+func (c *stubConn) Begin() (driver.Tx, error) {
+	c.inTx = true
+	return stubTx{c}, nil
+}
+
+type stubTx struct {
+	c *stubConn
+}
+
+func (tx stubTx) Commit() error {
+	tx.c.inTx = false
+	return nil
+}
+
+func (tx stubTx) Rollback() error {
+	tx.c.inTx = false
+	return nil
+}
+
+type stubStmt struct {
+	c     *stubConn
+	query string
+}
+
+func (s stubStmt) Close() error { return nil }
+
+func (s stubStmt) NumInput() int { return -1 }
+
+func (s stubStmt) Exec([]driver.Value) (driver.Result, error) {
+	s.c.d.record(s.query, s.c.inTx)
+	if s.c.d.execErr != nil {
+		return nil, s.c.d.execErr
+	}
+	return driver.RowsAffected(s.c.d.nextRowsAffected()), nil
+}
+
+func (s stubStmt) Query([]driver.Value) (driver.Rows, error) {
+	s.c.d.record(s.query, s.c.inTx)
+	return stubRows{}, nil
+}
+
+type stubRows struct{}
+
+func (stubRows) Columns() []string         { return nil }
+func (stubRows) Close() error              { return nil }
+func (stubRows) Next([]driver.Value) error { return io.EOF }
+
+func (d *stubDriver) Connect(context.Context) (driver.Conn, error) {
+	return &stubConn{d: d}, nil
+}
+
+func (d *stubDriver) Driver() driver.Driver {
+	return d
+}
+
+// newStubDB returns a db backed by a new stubDriver
+func newStubDB(t *testing.T) (*sql.DB, *stubDriver) {
+	d := &stubDriver{}
+	db := sql.OpenDB(d)
+	t.Cleanup(func() { db.Close() })
+	return db, d
+}
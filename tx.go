@@ -0,0 +1,50 @@
+package dbmanager
+
+import "fmt"
+
+// inTx runs fn with a copy of the dbManager running its queries within a new
+// transaction, committed if fn succeeds and rolled back otherwise (including
+// when fn fails the test). If a transaction is already in progress, fn runs
+// within it.
+func (dbMan *dbManager) inTx(fn func(*dbManager) error) (err error) {
+	if dbMan.tx != nil {
+		return fn(dbMan)
+	}
+
+	tx, err := dbMan.db.Begin()
+	if err != nil {
+		return fmt.Errorf("could not begin transaction: %w", err)
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			tx.Rollback()
+		}
+	}()
+
+	txMan := *dbMan
+	txMan.tx = tx
+	if err := fn(&txMan); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("could not commit transaction: %w", err)
+	}
+	committed = true
+	return nil
+}
+
+// Batch runs `fn` with a DBManager running its queries within a single
+// transaction, committed once `fn` returns. The transaction is rolled back if
+// `fn` fails the test, and the test fails if it can't be committed.
+// Nested batches run within the outer batch transaction.
+func (dbMan *dbManager) Batch(fn func(DBManager)) {
+	err := dbMan.inTx(func(txMan *dbManager) error {
+		fn(txMan)
+		return nil
+	})
+	if err != nil {
+		dbMan.t.Fatalf("Test setup failed: could not run batch: %+v", err)
+	}
+}
@@ -0,0 +1,24 @@
+package dbmanager
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestBatchUniqueInDB(t *testing.T) {
+	db, d := newStubDB(t)
+	dbMan := New(db, t, map[string]RelationValues{"users": {}})
+
+	var n int
+	email := dbMan.UniqueInDB("users", "email", func() interface{} {
+		n++
+		return fmt.Sprintf("user%d@example.com", n)
+	})
+	dbMan.Batch(func(m DBManager) {
+		m.Create("users", SetFieldValue("email", email))
+	})
+
+	if len(d.queries) != 2 || len(d.txQueries) != 2 {
+		t.Errorf("expected the uniqueness check and the insert to run within the batch transaction, got %q (%q within it)", d.queries, d.txQueries)
+	}
+}
@@ -0,0 +1,56 @@
+package dbmanager
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Int64 returns the value of the field `f` as an int64.
+// It panics if the value can't be represented as an integer.
+func (values RelationValues) Int64(f string) int64 {
+	switch v := values[f].(type) {
+	case int64:
+		return v
+	case int:
+		return int64(v)
+	case int32:
+		return int64(v)
+	case int16:
+		return int64(v)
+	case int8:
+		return int64(v)
+	case uint32:
+		return int64(v)
+	case uint16:
+		return int64(v)
+	case uint8:
+		return int64(v)
+	case []byte:
+		return parseInt64(f, string(v))
+	case string:
+		return parseInt64(f, v)
+	default:
+		panic(fmt.Sprintf("dbmanager: field '%s' (%T) is not an integer", f, v))
+	}
+}
+
+// String returns the value of the field `f` as a string.
+// It panics if the value isn't a string or a byte slice.
+func (values RelationValues) String(f string) string {
+	switch v := values[f].(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	default:
+		panic(fmt.Sprintf("dbmanager: field '%s' (%T) is not a string", f, v))
+	}
+}
+
+func parseInt64(f, s string) int64 {
+	i, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		panic(fmt.Sprintf("dbmanager: field '%s' (%q) is not an integer", f, s))
+	}
+	return i
+}
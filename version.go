@@ -0,0 +1,48 @@
+package dbmanager
+
+import (
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+// versionColumn represents an optimistic locking version column
+type versionColumn struct {
+	column string
+	start  int
+}
+
+// WithVersionColumn is used for creating an Option that sets `column` of the
+// given relation to `start` for created records whose values don't include it
+// (values set through options still take precedence).
+func WithVersionColumn(relation, column string, start int) Option {
+	return func(dbMan *dbManager) {
+		dbMan.versionColumns[relation] = versionColumn{column: column, start: start}
+	}
+}
+
+// IncrementVersion increments the version column (see WithVersionColumn) of
+// the records of the relation specified by `tableName` matching `where`.
+// The test fails if no records are matched.
+func (dbMan *dbManager) IncrementVersion(tableName string, where RelationValues) {
+	vc, ok := dbMan.versionColumns[tableName]
+	if !ok {
+		dbMan.t.Fatalf("no version column configured for relation '%s'", tableName)
+	}
+
+	column := dbMan.quoteColumn(vc.column)
+	query := dbMan.updateBuilder(tableName).
+		Set(column, sq.Expr(fmt.Sprintf("%s + 1", column))).
+		Where(dbMan.where(where))
+	res, err := dbMan.exec(tableName, query)
+	if err != nil {
+		dbMan.t.Fatalf("could not increment the version of records for '%s': %+v", tableName, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		dbMan.t.Fatalf("could not increment the version of records for '%s': %+v", tableName, err)
+	}
+	if n == 0 {
+		dbMan.t.Fatalf("could not increment the version of records for '%s': no records matching %v", tableName, where)
+	}
+}